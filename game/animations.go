@@ -0,0 +1,237 @@
+package game
+
+// Animation lengths in game milliseconds. They only change what is drawn, the
+// rules never wait for an animation, so a seed plays out the same with or
+// without them.
+const (
+	clearFlashTime    = 200
+	clearDissolveTime = 200
+	clearTime         = clearFlashTime + clearDissolveTime
+	trailTime         = 150
+	levelPulseTime    = 1000
+	levelPulsePeriod  = 250
+)
+
+// Animations follows a game's events and draws short effects on top of the
+// field. Everything is timed by the game clock, so pausing freezes them.
+type Animations struct {
+	Enabled bool
+
+	clears  []LinesCleared
+	trails  []HardDropped
+	levelUp *LevelUp
+}
+
+func NewAnimations() *Animations {
+	return &Animations{Enabled: true}
+}
+
+func (a *Animations) Subscribe(bus *EventBus) {
+	// The next piece changes the stack a running clear still shows
+	On(bus, func(e PieceLocked) {
+		a.clears = nil
+	})
+	On(bus, func(e LinesCleared) {
+		if a.Enabled {
+			a.clears = append(a.clears, e)
+		}
+	})
+	On(bus, func(e HardDropped) {
+		if a.Enabled && e.Distance > 0 {
+			a.trails = append(a.trails, e)
+		}
+	})
+	On(bus, func(e LevelUp) {
+		if a.Enabled {
+			a.levelUp = &e
+		}
+	})
+}
+
+// Skip ends all running animations
+func (a *Animations) Skip() {
+	a.clears = nil
+	a.trails = nil
+	a.levelUp = nil
+}
+
+// Active reports whether anything is still animating at game time now
+func (a *Animations) Active(now int64) bool {
+	a.expire(now)
+	return len(a.clears) > 0 || len(a.trails) > 0 || a.levelUp != nil
+}
+
+// expire drops finished animations, and stale ones from before a reset
+func (a *Animations) expire(now int64) {
+	running := func(at, length int64) bool {
+		return now >= at && now-at < length
+	}
+
+	clears := a.clears[:0]
+	for _, clear := range a.clears {
+		if now >= clear.At && now < clearStart(clear)+clearTime {
+			clears = append(clears, clear)
+		}
+	}
+	a.clears = clears
+
+	trails := a.trails[:0]
+	for _, trail := range a.trails {
+		if running(trail.At, trailTime) {
+			trails = append(trails, trail)
+		}
+	}
+	a.trails = trails
+
+	if a.levelUp != nil && !running(a.levelUp.At, levelPulseTime) {
+		a.levelUp = nil
+	}
+}
+
+// LevelPulse reports whether the LEVEL label is lit, it blinks for a moment
+// after a level up
+func (a *Animations) LevelPulse(now int64) bool {
+	a.expire(now)
+	return a.levelUp != nil && (now-a.levelUp.At)/levelPulsePeriod%2 == 0
+}
+
+// DrawAnimations draws the running effects for the game onto the field
+func (ui *Interface) DrawAnimations(game *Game) {
+	a := ui.Animations
+	now := game.timer.elapsed
+	a.expire(now)
+	if len(a.trails) == 0 && len(a.clears) == 0 {
+		return
+	}
+
+	// A clear puts the stack back the way it was until its rows are gone
+	stack := game.placedBlocks
+	clear := a.showingClear(now)
+	if clear != nil {
+		stack = clear.Stack
+	}
+
+	filled := filledCells(stack, game.Player.CurrentPolymino)
+	if clear != nil {
+		ui.drawClear(*clear, now, game.Player.CurrentPolymino)
+	}
+	for _, trail := range a.trails {
+		ui.drawTrail(trail, now, filled)
+	}
+}
+
+// clearStart is when a clear's animation begins. The clears of a chain share
+// the lock's time, each link waits for the one before it.
+func clearStart(clear LinesCleared) int64 {
+	return clear.At + int64(clear.Chain-1)*clearTime
+}
+
+// showingClear returns the clear animating at now, the last link of a chain
+// that has started
+func (a *Animations) showingClear(now int64) *LinesCleared {
+	var showing *LinesCleared
+	for i, clear := range a.clears {
+		if clearStart(clear) <= now && (showing == nil || clearStart(clear) >= clearStart(*showing)) {
+			showing = &a.clears[i]
+		}
+	}
+	return showing
+}
+
+// filledCells are the cells of the stack and the active piece, the trail
+// only draws on the other cells
+func filledCells(stack []Block, piece *Polyomino) map[Position]bool {
+	filled := make(map[Position]bool, len(stack))
+	for _, block := range stack {
+		filled[block.Position] = true
+	}
+	if piece != nil {
+		for _, cell := range absoluteCells(piece) {
+			filled[cell] = true
+		}
+	}
+	return filled
+}
+
+// drawTrail streaks the columns the piece fell through, shrinking towards
+// where it landed
+func (ui *Interface) drawTrail(trail HardDropped, now int64, filled map[Position]bool) {
+	r := ui.renderer
+	length := int(int64(trail.Distance) * (trailTime - (now - trail.At)) / trailTime)
+
+	for _, cell := range trail.Cells {
+		for y := cell.Y - length; y < cell.Y; y++ {
+			if y < 0 || occupied(trail.Cells, cell.X, y) || filled[Position{X: cell.X, Y: y}] {
+				continue
+			}
+			screenX, screenY := r.GameToScreenCoordinates(cell.X, y)
+			r.Pixels[screenY][screenX] = ColoredPixel{Char: r.glyphs.Trail[0], Color: piecePixel + trail.Color}
+			r.Pixels[screenY][screenX+1] = ColoredPixel{Char: r.glyphs.Trail[1], Color: piecePixel + trail.Color}
+		}
+	}
+}
+
+// drawClear redraws the field from the stack as it was before the clear,
+// with the cleared rows flashing and then dissolving from the middle
+// outwards. The active piece stays on top.
+func (ui *Interface) drawClear(clear LinesCleared, now int64, piece *Polyomino) {
+	r := ui.renderer
+	age := now - clearStart(clear)
+
+	color := "white"
+	if age/50%2 == 1 {
+		color = "yellow"
+	}
+
+	gone := 0
+	if age > clearFlashTime {
+		gone = int((age - clearFlashTime) * (GameFieldWidth/2 + 1) / clearDissolveTime)
+	}
+
+	cleared := make(map[int]bool, len(clear.Rows))
+	for _, y := range clear.Rows {
+		cleared[y] = true
+	}
+
+	var active []Position
+	if piece != nil {
+		active = absoluteCells(piece)
+	}
+
+	stack := make(map[Position]Block, len(clear.Stack))
+	for _, block := range clear.Stack {
+		stack[block.Position] = block
+	}
+
+	middle := GameFieldWidth / 2
+	for y := 0; y < GameFieldHeight; y++ {
+		for x := 0; x < GameFieldWidth; x++ {
+			if occupied(active, x, y) {
+				continue
+			}
+
+			left, right := ColoredPixel{Char: ' '}, ColoredPixel{Char: ' '}
+			switch block, ok := stack[Position{X: x, Y: y}]; {
+			case cleared[y] && abs(x-middle) >= gone:
+				left = ColoredPixel{Char: r.glyphs.Block[0], Color: color}
+				right = ColoredPixel{Char: r.glyphs.Block[1], Color: color}
+			case ok && !cleared[y]:
+				left = ColoredPixel{Char: r.glyphs.Block[0], Color: piecePixel + block.Color}
+				right = ColoredPixel{Char: r.glyphs.Block[1], Color: piecePixel + block.Color}
+			}
+
+			screenX, screenY := r.GameToScreenCoordinates(x, y)
+			r.Pixels[screenY][screenX] = left
+			r.Pixels[screenY][screenX+1] = right
+		}
+	}
+}
+
+func occupied(cells []Position, x, y int) bool {
+	for _, cell := range cells {
+		if cell.X == x && cell.Y == y {
+			return true
+		}
+	}
+	return false
+}
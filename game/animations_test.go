@@ -0,0 +1,104 @@
+package game
+
+import "testing"
+
+func TestShowingClear(t *testing.T) {
+	chain := []LinesCleared{
+		{At: 1000, Chain: 1, Rows: []int{24}},
+		{At: 1000, Chain: 2, Rows: []int{23}},
+	}
+
+	tests := []struct {
+		name string
+		now  int64
+		want int // Chain link on screen, 0 for none
+	}{
+		{"before the lock", 999, 0},
+		{"first link flashes", 1000, 1},
+		{"first link dissolves", 1000 + clearFlashTime, 1},
+		{"second link follows", 1000 + clearTime, 2},
+		{"second link dissolves", 1000 + clearTime + clearFlashTime, 2},
+		{"all done", 1000 + 2*clearTime, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := NewAnimations()
+			a.clears = append([]LinesCleared(nil), chain...)
+			a.expire(tt.now)
+
+			got := 0
+			if clear := a.showingClear(tt.now); clear != nil {
+				got = clear.Chain
+			}
+			if got != tt.want {
+				t.Errorf("showing link %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClearAnimationKeepsTheStack(t *testing.T) {
+	g := NewHeadlessGame(1)
+	a := NewAnimations()
+	a.Subscribe(g.Events)
+
+	g.placedBlocks = pieceBlocks("4", "###############")
+	g.CheckLineClear()
+
+	clear := a.showingClear(g.timer.elapsed)
+	if clear == nil {
+		t.Fatal("no clear animating")
+	}
+	if len(clear.Stack) != GameFieldWidth+1 {
+		t.Errorf("animation has %d blocks, want the %d from before the clear", len(clear.Stack), GameFieldWidth+1)
+	}
+
+	// The next lock ends the picture of the old stack
+	g.publish(PieceLocked{At: g.timer.elapsed})
+	if a.showingClear(g.timer.elapsed) != nil {
+		t.Error("clear still animating after the next lock")
+	}
+}
+
+func TestDrawClearShowsTheRowsBeforeTheyGo(t *testing.T) {
+	r := &Renderer{glyphs: DetectGlyphSet(), view: DefaultViewport}
+	r.Pixels = make([][]ColoredPixel, GameFieldEndY+1)
+	for y := range r.Pixels {
+		r.Pixels[y] = make([]ColoredPixel, ScreenUnitWidth)
+	}
+	ui := NewInterfaceAt(r, DefaultViewport)
+
+	g := NewHeadlessGame(1)
+	ui.Animations.Subscribe(g.Events)
+	g.placedBlocks = pieceBlocks("4", "###############")
+	g.CheckLineClear()
+
+	pixel := func(x, y int) ColoredPixel {
+		screenX, screenY := r.GameToScreenCoordinates(x, y)
+		return r.Pixels[screenY][screenX]
+	}
+
+	tests := []struct {
+		name      string
+		age       int64
+		x, y      int
+		wantColor string
+	}{
+		{"block above keeps its place", 0, 0, GameFieldHeight - 2, piecePixel + "red"},
+		{"row flashes under the block that falls into it", 0, 0, GameFieldHeight - 1, "white"},
+		{"row flashes at the wall", 50, GameFieldWidth - 1, GameFieldHeight - 1, "yellow"},
+		{"row dissolves from the middle", clearTime - 1, GameFieldWidth / 2, GameFieldHeight - 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clear := ui.Animations.showingClear(g.timer.elapsed)
+			ui.drawClear(*clear, g.timer.elapsed+tt.age, nil)
+
+			if got := pixel(tt.x, tt.y).Color; got != tt.wantColor {
+				t.Errorf("cell %d,%d has color %q, want %q", tt.x, tt.y, got, tt.wantColor)
+			}
+		})
+	}
+}
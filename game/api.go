@@ -0,0 +1,197 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// apiTimeout bounds how long a request waits for the game loop to pick it up
+const apiTimeout = 2 * time.Second
+
+// APIActions are the processInput actions that can be POSTed to /api/action
+var APIActions = map[string]bool{
+	"up":       true,
+	"down":     true,
+	"left":     true,
+	"right":    true,
+	"space":    true,
+	"swap":     true,
+	"hardDrop": true,
+}
+
+// APIState is the JSON document served by GET /api/state
+type APIState struct {
+	SpectatorFrame
+	Seed   int64 `json:"seed"`
+	Paused bool  `json:"paused"`
+}
+
+type apiResponse struct {
+	status int
+	body   any
+}
+
+// apiCommand carries a request into the game loop, which runs it and replies.
+// All game state is only ever touched from the loop goroutine.
+type apiCommand struct {
+	run   func(g *Game) apiResponse
+	reply chan apiResponse
+}
+
+// APIServer is an optional local HTTP server to inspect and drive a live game
+type APIServer struct {
+	server   *http.Server
+	listener net.Listener
+	commands chan apiCommand
+}
+
+func StartAPI(addr string) (*APIServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	api := &APIServer{
+		listener: listener,
+		commands: make(chan apiCommand),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/state", api.handleState)
+	mux.HandleFunc("POST /api/action", api.handleAction)
+	mux.HandleFunc("POST /api/control", api.handleControl)
+	api.server = &http.Server{Handler: mux, ReadHeaderTimeout: apiTimeout}
+
+	go func() {
+		if err := api.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			GetLoggerInstance().Error("API server stopped", "err", err)
+		}
+	}()
+
+	GetLoggerInstance().Info("API listening", "addr", listener.Addr().String())
+	return api, nil
+}
+
+func (a *APIServer) Addr() net.Addr {
+	return a.listener.Addr()
+}
+
+func (a *APIServer) Close() error {
+	return a.server.Close()
+}
+
+// Commands is read by the game loop
+func (a *APIServer) Commands() <-chan apiCommand {
+	return a.commands
+}
+
+func (a *APIServer) dispatch(w http.ResponseWriter, run func(g *Game) apiResponse) {
+	cmd := apiCommand{run: run, reply: make(chan apiResponse, 1)}
+
+	select {
+	case a.commands <- cmd:
+	case <-time.After(apiTimeout):
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "game loop is not running"})
+		return
+	}
+
+	response := <-cmd.reply
+	writeJSON(w, response.status, response.body)
+}
+
+func (a *APIServer) handleState(w http.ResponseWriter, r *http.Request) {
+	a.dispatch(w, func(g *Game) apiResponse {
+		return apiResponse{status: http.StatusOK, body: g.APIState()}
+	})
+}
+
+func (a *APIServer) handleAction(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Action string `json:"action"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if !APIActions[request.Action] {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown action " + request.Action})
+		return
+	}
+
+	a.dispatch(w, func(g *Game) apiResponse {
+		if g.IsGameOver || g.Paused || g.pendingResume != nil {
+			return apiResponse{status: http.StatusConflict, body: map[string]string{"error": "game is not running"}}
+		}
+
+		g.processInput(Event{Action: request.Action})
+		return apiResponse{status: http.StatusOK, body: g.APIState()}
+	})
+}
+
+func (a *APIServer) handleControl(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Command string `json:"command"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+
+	var control func(g *Game)
+	switch request.Command {
+	case "start":
+		control = func(g *Game) {
+			if g.IsGameOver {
+				g.Reset()
+			}
+			g.Resume()
+		}
+	case "pause":
+		control = (*Game).Pause
+	case "reset":
+		control = (*Game).Reset
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "unknown command " + request.Command})
+		return
+	}
+
+	a.dispatch(w, func(g *Game) apiResponse {
+		// Like the keys, nothing pauses or restarts one board of a match
+		if g.Versus != nil {
+			return apiResponse{status: http.StatusConflict, body: map[string]string{"error": "cannot " + request.Command + " a versus match"}}
+		}
+
+		control(g)
+		return apiResponse{status: http.StatusOK, body: g.APIState()}
+	})
+}
+
+func (g *Game) APIState() APIState {
+	return APIState{
+		SpectatorFrame: NewSpectatorFrame(g),
+		Seed:           g.Seed,
+		Paused:         g.Paused,
+	}
+}
+
+// apiCommands returns the API command channel, or nil (which blocks forever
+// in a select) when no API server is attached.
+func (g *Game) apiCommands() <-chan apiCommand {
+	if g.API == nil {
+		return nil
+	}
+	return g.API.Commands()
+}
+
+func (g *Game) handleAPICommand(cmd apiCommand) {
+	cmd.reply <- cmd.run(g)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -1,40 +1,63 @@
 package game
 
-import "fmt"
+// SwapBlocks swaps the current piece with the next one, once per piece. It
+// reports whether the pieces were swapped.
+func (g *Game) SwapBlocks() bool {
+	if g.Player.CurrentPolymino == nil || g.Player.NextPolyomino == nil || g.Player.HasSwapped {
+		GetLoggerInstance().Debug("Cannot swap - already swapped or no active block")
+		return false
+	}
 
-func (g *Game) SwapBlocks() {
-	if g.Player.CurrentPolymino == nil || g.Player.HasSwapped {
-		GetLoggerInstance().Log("Cannot swap - already swapped or no active block")
-		return
+	// Both pieces go back to the spawn point, and both have to fit there: the
+	// held piece comes back later without another check
+	currentSpawn := SpawnPosition(g.Player.NextPolyomino.Blocks)
+	nextSpawn := SpawnPosition(g.Player.CurrentPolymino.Blocks)
+	if !g.fitsAt(g.Player.NextPolyomino, currentSpawn) || !g.fitsAt(g.Player.CurrentPolymino, nextSpawn) {
+		GetLoggerInstance().Debug("Cannot swap - collision detected")
+		return false
 	}
 
-	originalPiece := g.Player.CurrentPolymino
-	originalNextPiece := g.Player.NextPolyomino
+	g.Player.CurrentPolymino, g.Player.NextPolyomino = g.Player.NextPolyomino, g.Player.CurrentPolymino
+	g.Player.CurrentPolymino.Position = currentSpawn
+	g.Player.NextPolyomino.Position = nextSpawn
 
-	g.Player.CurrentPolymino = originalNextPiece
-	g.Player.NextPolyomino = originalPiece
+	g.Player.HasSwapped = true
+	g.publish(HoldUsed{
+		At:    g.timer.elapsed,
+		Size:  len(g.Player.CurrentPolymino.Blocks),
+		Color: pieceColor(g.Player.CurrentPolymino),
+	})
+	return true
+}
 
-	lowestY := GetLowestBlockPosition(g.Player.CurrentPolymino.Blocks).Y
-	g.Player.CurrentPolymino.Position = Position{
-		X: GameFieldWidth / 2,
-		Y: -lowestY - 1,
+// fitsAt reports whether piece would be inside the field and clear of the
+// stack at position. Rows above the field count as free.
+func (g *Game) fitsAt(piece *Polyomino, position Position) bool {
+	occupied := make(map[Position]bool, len(g.placedBlocks))
+	for _, block := range g.placedBlocks {
+		occupied[block.Position] = true
 	}
 
-	lowestNextY := GetLowestBlockPosition(g.Player.NextPolyomino.Blocks).Y
-	g.Player.NextPolyomino.Position = Position{
-		X: GameFieldWidth / 2,
-		Y: -lowestNextY - 1,
+	for _, block := range piece.Blocks {
+		at := Position{X: position.X + block.Position.X, Y: position.Y + block.Position.Y}
+		if at.X < 0 || at.X >= GameFieldWidth || at.Y >= GameFieldHeight || occupied[at] {
+			return false
+		}
 	}
+	return true
+}
 
-	if g.checkCollision() {
-		g.Player.CurrentPolymino = originalPiece
-		g.Player.NextPolyomino = originalNextPiece
+// DropDistance is how many rows the current piece can fall before it lands
+func (g *Game) DropDistance() int {
+	if g.Player.CurrentPolymino == nil {
+		return 0
+	}
 
-		GetLoggerInstance().Log("Cannot swap - collision detected")
-	} else {
-		g.Player.HasSwapped = true
-		GetLoggerInstance().Log("Blocks swapped successfully")
+	distance := 0
+	for !g.checkMovementCollision(0, distance+1) {
+		distance++
 	}
+	return distance
 }
 
 func (g *Game) HardDrop() {
@@ -48,7 +71,13 @@ func (g *Game) HardDrop() {
 		movesMade++
 	}
 
+	g.publish(HardDropped{
+		At:       g.timer.elapsed,
+		Distance: movesMade,
+		Color:    pieceColor(g.Player.CurrentPolymino),
+		Cells:    absoluteCells(g.Player.CurrentPolymino),
+	})
 	g.placeCurrentPolyomino()
 
-	GetLoggerInstance().Log("Hard dropped block by " + fmt.Sprintf("%d", movesMade) + " rows")
+	GetLoggerInstance().Debug("Hard dropped block", "rows", movesMade)
 }
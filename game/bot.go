@@ -0,0 +1,319 @@
+package game
+
+import "math"
+
+// BotWeights are multiplied with the board features of a candidate placement.
+// Penalties are expressed as negative weights.
+type BotWeights struct {
+	AggregateHeight float64 `json:"aggregateHeight"`
+	Holes           float64 `json:"holes"`
+	Bumpiness       float64 `json:"bumpiness"`
+	LinesCleared    float64 `json:"linesCleared"`
+	Wells           float64 `json:"wells"`
+}
+
+func DefaultBotWeights() BotWeights {
+	return BotWeights{
+		AggregateHeight: -0.510066,
+		Holes:           -0.35663,
+		Bumpiness:       -0.184483,
+		LinesCleared:    0.760666,
+		Wells:           -0.05,
+	}
+}
+
+// toppedOutPenalty is applied per block left above the field, so placements
+// that end the game are only chosen when nothing else fits.
+const toppedOutPenalty = 1000
+
+type BoardFeatures struct {
+	AggregateHeight int
+	Holes           int
+	Bumpiness       int
+	LinesCleared    int
+	Wells           int
+	ToppedOut       int
+}
+
+func (w BotWeights) Evaluate(f BoardFeatures) float64 {
+	return w.AggregateHeight*float64(f.AggregateHeight) +
+		w.Holes*float64(f.Holes) +
+		w.Bumpiness*float64(f.Bumpiness) +
+		w.LinesCleared*float64(f.LinesCleared) +
+		w.Wells*float64(f.Wells) -
+		toppedOutPenalty*float64(f.ToppedOut)
+}
+
+// Placement is a final resting spot for the active piece: the rotated shape
+// and the column its origin ends up in.
+type Placement struct {
+	Blocks []Block
+	X      int
+	Y      int
+	Score  float64
+}
+
+type Bot struct {
+	Weights BotWeights
+
+	piece     *Polyomino
+	target    *Placement
+	rotations int
+	lastX     int
+	stuck     int
+}
+
+func NewBot(weights BotWeights) *Bot {
+	return &Bot{Weights: weights}
+}
+
+// NextAction returns the next processInput action that moves the active piece
+// towards the best placement, planning a new one whenever a piece spawns.
+func (b *Bot) NextAction(g *Game) string {
+	current := g.Player.CurrentPolymino
+	if current == nil {
+		return ""
+	}
+
+	if b.piece != current {
+		b.piece = current
+		b.target = b.BestPlacement(g.placedBlocks, current)
+		b.rotations = 0
+		b.lastX = current.Position.X
+		b.stuck = 0
+
+		if b.target != nil {
+			GetLoggerInstance().Debug("Bot target", "x", b.target.X, "score", b.target.Score)
+		}
+	}
+
+	if b.target == nil {
+		return "hardDrop"
+	}
+
+	if !sameShape(current.Blocks, b.target.Blocks) && b.rotations < 4 {
+		b.rotations++
+		return "up"
+	}
+
+	// Give up on the column if the piece stopped moving, e.g. blocked by the stack
+	if current.Position.X == b.lastX {
+		b.stuck++
+	} else {
+		b.stuck = 0
+	}
+	b.lastX = current.Position.X
+
+	if b.stuck < 3 {
+		if current.Position.X < b.target.X {
+			return "right"
+		}
+		if current.Position.X > b.target.X {
+			return "left"
+		}
+	}
+
+	return "hardDrop"
+}
+
+// BestPlacement tries every rotation in every column and returns the placement
+// with the highest score, or nil if the piece cannot be placed at all.
+func (b *Bot) BestPlacement(placed []Block, piece *Polyomino) *Placement {
+	board := NewBoard(placed)
+
+	var best *Placement
+	for _, shape := range Rotations(piece) {
+		minX, maxX := shape[0].Position.X, shape[0].Position.X
+		for _, block := range shape {
+			minX = min(minX, block.Position.X)
+			maxX = max(maxX, block.Position.X)
+		}
+
+		for x := -minX; x+maxX < GameFieldWidth; x++ {
+			y := piece.Position.Y
+			if !board.Fits(shape, x, y) {
+				continue
+			}
+			for board.Fits(shape, x, y+1) {
+				y++
+			}
+
+			features := board.WithPiece(shape, x, y).Features()
+			score := b.Weights.Evaluate(features)
+			if best == nil || score > best.Score {
+				best = &Placement{Blocks: shape, X: x, Y: y, Score: score}
+			}
+		}
+	}
+
+	return best
+}
+
+// Rotations returns the distinct shapes the piece takes over a full turn
+func Rotations(piece *Polyomino) [][]Block {
+	probe := &Polyomino{
+		Blocks:        make([]Block, len(piece.Blocks)),
+		RotationPoint: piece.RotationPoint,
+	}
+	copy(probe.Blocks, piece.Blocks)
+
+	shapes := [][]Block{}
+	for r := 0; r < 4; r++ {
+		duplicate := false
+		for _, shape := range shapes {
+			if sameShape(shape, probe.Blocks) {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			shape := make([]Block, len(probe.Blocks))
+			copy(shape, probe.Blocks)
+			shapes = append(shapes, shape)
+		}
+		probe.Rotate(true)
+	}
+
+	return shapes
+}
+
+func sameShape(a, b []Block) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Position != b[i].Position {
+			return false
+		}
+	}
+	return true
+}
+
+// Board is an occupancy grid of the game field used for quick lookahead
+type Board struct {
+	Cells [GameFieldHeight][GameFieldWidth]bool
+	Above int // Blocks that ended up above the top of the field
+	Lines int // Lines cleared while building the board
+}
+
+func NewBoard(placed []Block) *Board {
+	board := &Board{}
+	for _, block := range placed {
+		if board.inside(block.Position.X, block.Position.Y) {
+			board.Cells[block.Position.Y][block.Position.X] = true
+		}
+	}
+	return board
+}
+
+func (b *Board) inside(x, y int) bool {
+	return x >= 0 && x < GameFieldWidth && y >= 0 && y < GameFieldHeight
+}
+
+// Fits reports whether the shape can sit with its origin at x, y. Cells above
+// the field are free, matching checkMovementCollision.
+func (b *Board) Fits(shape []Block, x, y int) bool {
+	for _, block := range shape {
+		absX := x + block.Position.X
+		absY := y + block.Position.Y
+		if absX < 0 || absX >= GameFieldWidth || absY >= GameFieldHeight {
+			return false
+		}
+		if absY >= 0 && b.Cells[absY][absX] {
+			return false
+		}
+	}
+	return true
+}
+
+// WithPiece returns a copy of the board with the shape locked in and any full
+// rows cleared.
+func (b *Board) WithPiece(shape []Block, x, y int) *Board {
+	next := *b
+	next.Lines = 0
+
+	for _, block := range shape {
+		absX := x + block.Position.X
+		absY := y + block.Position.Y
+		if absY < 0 {
+			next.Above++
+			continue
+		}
+		next.Cells[absY][absX] = true
+	}
+
+	next.clearFullRows()
+	return &next
+}
+
+func (b *Board) clearFullRows() {
+	for y := GameFieldHeight - 1; y >= 0; {
+		full := true
+		for x := 0; x < GameFieldWidth; x++ {
+			if !b.Cells[y][x] {
+				full = false
+				break
+			}
+		}
+
+		if !full {
+			y--
+			continue
+		}
+
+		for row := y; row > 0; row-- {
+			b.Cells[row] = b.Cells[row-1]
+		}
+		b.Cells[0] = [GameFieldWidth]bool{}
+		b.Lines++
+	}
+}
+
+func (b *Board) ColumnHeights() [GameFieldWidth]int {
+	var heights [GameFieldWidth]int
+	for x := 0; x < GameFieldWidth; x++ {
+		for y := 0; y < GameFieldHeight; y++ {
+			if b.Cells[y][x] {
+				heights[x] = GameFieldHeight - y
+				break
+			}
+		}
+	}
+	return heights
+}
+
+func (b *Board) Features() BoardFeatures {
+	heights := b.ColumnHeights()
+	features := BoardFeatures{
+		LinesCleared: b.Lines,
+		ToppedOut:    b.Above,
+	}
+
+	for x := 0; x < GameFieldWidth; x++ {
+		features.AggregateHeight += heights[x]
+
+		for y := GameFieldHeight - heights[x] + 1; y < GameFieldHeight; y++ {
+			if !b.Cells[y][x] {
+				features.Holes++
+			}
+		}
+
+		if x > 0 {
+			features.Bumpiness += int(math.Abs(float64(heights[x] - heights[x-1])))
+		}
+
+		// The walls count as infinitely high neighbours
+		left, right := GameFieldHeight, GameFieldHeight
+		if x > 0 {
+			left = heights[x-1]
+		}
+		if x < GameFieldWidth-1 {
+			right = heights[x+1]
+		}
+		if depth := min(left, right) - heights[x]; depth > 0 {
+			features.Wells += depth
+		}
+	}
+
+	return features
+}
@@ -0,0 +1,93 @@
+package game
+
+import "time"
+
+// BotInput lets the bot play through the event path the keyboard and scripts
+// use. It wraps the game's input: Esc and the other keys keep working, and
+// every botActionInterval of game time the bot's next action is queued with
+// them.
+type BotInput struct {
+	inner      InputSource
+	game       *Game
+	events     chan Event
+	lastAction int64
+}
+
+// EnableAutoplay lets bot play the game. Replace the input before calling it,
+// e.g. with a script, the bot then plays alongside the script.
+func (g *Game) EnableAutoplay(bot *Bot) {
+	g.Bot = bot
+	g.Input = NewBotInput(g, g.Input)
+}
+
+func NewBotInput(g *Game, inner InputSource) *BotInput {
+	return &BotInput{
+		inner:  inner,
+		game:   g,
+		events: make(chan Event, cap(inner.Events())+1),
+	}
+}
+
+// Start starts the wrapped input. Keys that arrive in their own time are
+// passed on as they come, stepped input is passed on in Step.
+func (b *BotInput) Start() error {
+	if err := b.inner.Start(); err != nil {
+		return err
+	}
+
+	if _, ok := b.inner.(steppedInput); !ok {
+		go func() {
+			for event := range b.inner.Events() {
+				b.events <- event
+			}
+		}()
+	}
+	return nil
+}
+
+// Step steps the wrapped input, then queues the bot's action if one is due
+func (b *BotInput) Step(d time.Duration) {
+	if inner, ok := b.inner.(steppedInput); ok {
+		inner.Step(d)
+		for queued := true; queued; {
+			select {
+			case event := <-inner.Events():
+				b.events <- event
+			default:
+				queued = false
+			}
+		}
+	}
+
+	if action := b.nextAction(); action != "" {
+		b.events <- Event{Action: action, Bot: true}
+	}
+}
+
+// nextAction asks the bot for a move once every botActionInterval of game time
+func (b *BotInput) nextAction() string {
+	g := b.game
+	if g.Bot == nil || g.IsGameOver || g.pendingResume != nil || g.Paused || g.console.Active {
+		return ""
+	}
+
+	// A reset winds the clock back, the bot starts over with it
+	if since := g.timer.elapsed - b.lastAction; since >= 0 && since < botActionInterval {
+		return ""
+	}
+	b.lastAction = g.timer.elapsed
+
+	return g.Bot.NextAction(g)
+}
+
+func (b *BotInput) Events() <-chan Event {
+	return b.events
+}
+
+func (b *BotInput) QuitChannel() <-chan bool {
+	return b.inner.QuitChannel()
+}
+
+func (b *BotInput) Stop() {
+	b.inner.Stop()
+}
@@ -0,0 +1,206 @@
+package game
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// boardBlocks turns rows of '#' and '.' into blocks. The rows sit on the
+// floor, the last one is the bottom row of the field.
+func boardBlocks(rows ...string) []Block {
+	var blocks []Block
+	top := GameFieldHeight - len(rows)
+	for i, row := range rows {
+		for x, c := range row {
+			if c == '#' {
+				blocks = append(blocks, Block{Position: Position{X: x, Y: top + i}, Color: "red"})
+			}
+		}
+	}
+	return blocks
+}
+
+func TestBoardFeatures(t *testing.T) {
+	tests := []struct {
+		name string
+		rows []string
+		want BoardFeatures
+	}{
+		{
+			name: "empty",
+			want: BoardFeatures{},
+		},
+		{
+			name: "one block",
+			rows: []string{"#.............."},
+			want: BoardFeatures{AggregateHeight: 1, Bumpiness: 1},
+		},
+		{
+			name: "covered hole",
+			rows: []string{"#..............", "..............."},
+			want: BoardFeatures{AggregateHeight: 2, Holes: 1, Bumpiness: 2},
+		},
+		{
+			name: "well in the middle",
+			rows: []string{"#######.#######"},
+			want: BoardFeatures{AggregateHeight: 14, Bumpiness: 2, Wells: 1},
+		},
+		{
+			name: "wells next to the walls",
+			rows: []string{".#############.", ".#############."},
+			want: BoardFeatures{AggregateHeight: 26, Bumpiness: 4, Wells: 4},
+		},
+		{
+			name: "deep well",
+			rows: []string{"#.#", "#.#", "#.#", "#.#"},
+			want: BoardFeatures{AggregateHeight: 8, Bumpiness: 12, Wells: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewBoard(boardBlocks(tt.rows...)).Features(); got != tt.want {
+				t.Errorf("Features() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBoardWithPiece(t *testing.T) {
+	vertical := []Block{{Position: Position{X: 0, Y: -1}}, {Position: Position{X: 0, Y: 0}}, {Position: Position{X: 0, Y: 1}}}
+
+	tests := []struct {
+		name string
+		rows []string
+		x, y int
+		want BoardFeatures
+	}{
+		{
+			name: "clears the rows it fills",
+			rows: []string{"##############.", "##############.", "##############."},
+			x:    14, y: GameFieldHeight - 2,
+			want: BoardFeatures{LinesCleared: 3},
+		},
+		{
+			name: "keeps rows with gaps",
+			rows: []string{"#############..", "##############."},
+			x:    14, y: GameFieldHeight - 2,
+			want: BoardFeatures{AggregateHeight: 13 + 2, Bumpiness: 1 + 2, Wells: 1, LinesCleared: 1},
+		},
+		{
+			name: "counts blocks above the field",
+			x:    3, y: 0,
+			want: BoardFeatures{AggregateHeight: GameFieldHeight, Holes: GameFieldHeight - 2, Bumpiness: 2 * GameFieldHeight, ToppedOut: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := NewBoard(boardBlocks(tt.rows...))
+			if got := board.WithPiece(vertical, tt.x, tt.y).Features(); got != tt.want {
+				t.Errorf("Features() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBestPlacement(t *testing.T) {
+	monomino := []Position{{X: 0, Y: 0}}
+	tromino := []Position{{X: -1, Y: 0}, {X: 0, Y: 0}, {X: 1, Y: 0}}
+
+	tests := []struct {
+		name      string
+		rows      []string
+		cells     []Position
+		wantX     int
+		wantY     int
+		wantLines int
+	}{
+		{
+			name:      "fills the gap",
+			rows:      []string{"###.###########"},
+			cells:     monomino,
+			wantX:     3,
+			wantY:     GameFieldHeight - 1,
+			wantLines: 1,
+		},
+		{
+			name:      "stands up in the well",
+			rows:      []string{"##############.", "##############.", "##############."},
+			cells:     tromino,
+			wantX:     14,
+			wantY:     GameFieldHeight - 2,
+			wantLines: 3,
+		},
+		{
+			name:  "lies flat on an empty field",
+			cells: tromino,
+			wantX: 1,
+			wantY: GameFieldHeight - 1,
+		},
+	}
+
+	bot := NewBot(DefaultBotWeights())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			placed := boardBlocks(tt.rows...)
+			piece := NewSpawnPolyomino(tt.cells, "red")
+
+			best := bot.BestPlacement(placed, piece)
+			if best == nil {
+				t.Fatal("found no placement")
+			}
+			if best.X != tt.wantX || best.Y != tt.wantY {
+				t.Errorf("placed at %d,%d, want %d,%d", best.X, best.Y, tt.wantX, tt.wantY)
+			}
+			if lines := NewBoard(placed).WithPiece(best.Blocks, best.X, best.Y).Lines; lines != tt.wantLines {
+				t.Errorf("placement clears %d lines, want %d", lines, tt.wantLines)
+			}
+		})
+	}
+}
+
+func TestBestPlacementWithoutRoom(t *testing.T) {
+	var rows []string
+	for y := 0; y < GameFieldHeight; y++ {
+		rows = append(rows, "###############")
+	}
+
+	piece := NewPolyomino([]Block{{Position: Position{X: 0, Y: 0}}}, 7, 0, false)
+	if best := NewBot(DefaultBotWeights()).BestPlacement(boardBlocks(rows...), piece); best != nil {
+		t.Errorf("placed the piece at %d,%d on a full field", best.X, best.Y)
+	}
+}
+
+func TestAutoplayGoesThroughTheInput(t *testing.T) {
+	play := func() *Game {
+		script, err := ParseScript(strings.NewReader("wait 1h"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		g := NewHeadlessGame(5)
+		g.Input = script
+		g.EnableAutoplay(NewBot(DefaultBotWeights()))
+		if err := g.Input.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		for step := 0; step < 20*StepRate && !g.IsGameOver; step++ {
+			if !g.handleInputs() {
+				t.Fatal("input ended early")
+			}
+			g.Update()
+		}
+		return g
+	}
+
+	g := play()
+	if g.Stats.PiecesPlaced < 5 {
+		t.Errorf("bot placed %d pieces in 20s, want it to keep playing", g.Stats.PiecesPlaced)
+	}
+	if again := play(); !reflect.DeepEqual(again.placedBlocks, g.placedBlocks) {
+		t.Error("two runs with the same seed went apart")
+	}
+}
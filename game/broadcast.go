@@ -0,0 +1,235 @@
+package game
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+const BroadcastVersion = 1
+
+// clientBacklog is how many frames a slow spectator may fall behind before
+// frames are dropped for it. The game loop never waits on a spectator.
+const clientBacklog = 16
+
+// SpectatorFrame is one line of the broadcast stream
+type SpectatorFrame struct {
+	Version  int           `json:"v"`
+	Mode     string        `json:"mode"`
+	Board    []Block       `json:"board"`
+	Current  *Polyomino    `json:"current"`
+	Next     *Polyomino    `json:"next"`
+	Scoring  ScoringSystem `json:"scoring"`
+	Stats    Statistics    `json:"stats"`
+	Elapsed  int64         `json:"elapsedMs"`
+	GameOver bool          `json:"gameOver"`
+}
+
+func NewSpectatorFrame(g *Game) SpectatorFrame {
+	return SpectatorFrame{
+		Version:  BroadcastVersion,
+		Mode:     g.Mode,
+		Board:    g.placedBlocks,
+		Current:  g.Player.CurrentPolymino,
+		Next:     g.Player.NextPolyomino,
+		Scoring:  *g.Scoring,
+		Stats:    *g.Stats,
+		Elapsed:  g.timer.elapsed,
+		GameOver: g.IsGameOver,
+	}
+}
+
+// BroadcastServer streams the game to every connected spectator as
+// line-delimited JSON frames.
+type BroadcastServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	clients  map[net.Conn]chan []byte
+	last     []byte
+}
+
+func StartBroadcast(addr string) (*BroadcastServer, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	server := &BroadcastServer{
+		listener: listener,
+		clients:  make(map[net.Conn]chan []byte),
+	}
+	go server.acceptLoop()
+
+	GetLoggerInstance().Info("Broadcasting", "addr", listener.Addr().String())
+	return server, nil
+}
+
+func (b *BroadcastServer) Addr() net.Addr {
+	return b.listener.Addr()
+}
+
+func (b *BroadcastServer) acceptLoop() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				GetLoggerInstance().Error("Broadcast accept failed", "err", err)
+			}
+			return
+		}
+
+		frames := make(chan []byte, clientBacklog)
+
+		b.mu.Lock()
+		b.clients[conn] = frames
+		// Late joiners get the current picture right away
+		if b.last != nil {
+			frames <- b.last
+		}
+		b.mu.Unlock()
+
+		go b.writeLoop(conn, frames)
+	}
+}
+
+func (b *BroadcastServer) writeLoop(conn net.Conn, frames chan []byte) {
+	defer conn.Close()
+
+	for frame := range frames {
+		if _, err := conn.Write(frame); err != nil {
+			b.mu.Lock()
+			if _, ok := b.clients[conn]; ok {
+				delete(b.clients, conn)
+				close(frames)
+			}
+			b.mu.Unlock()
+
+			// Drain so nothing blocks on the closed channel
+			for range frames {
+			}
+			return
+		}
+	}
+}
+
+// Publish sends the current state to all spectators if it changed since the
+// last frame.
+func (b *BroadcastServer) Publish(g *Game) {
+	frame, err := json.Marshal(NewSpectatorFrame(g))
+	if err != nil {
+		GetLoggerInstance().Error("Could not encode broadcast frame", "err", err)
+		return
+	}
+	frame = append(frame, '\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if bytes.Equal(frame, b.last) {
+		return
+	}
+	b.last = frame
+
+	for _, frames := range b.clients {
+		select {
+		case frames <- frame:
+		default:
+			// Spectator is too slow, skip this frame for it
+		}
+	}
+}
+
+func (b *BroadcastServer) Close() error {
+	err := b.listener.Close()
+
+	b.mu.Lock()
+	for conn, frames := range b.clients {
+		delete(b.clients, conn)
+		close(frames)
+	}
+	b.mu.Unlock()
+
+	return err
+}
+
+// Watch connects to a broadcasting game and renders it read-only until the
+// stream ends or ESC is pressed.
+func Watch(addr string) error {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	frames := make(chan SpectatorFrame)
+	streamErr := make(chan error, 1)
+	go func() {
+		defer close(frames)
+
+		scanner := bufio.NewScanner(conn)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var frame SpectatorFrame
+			if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+				GetLoggerInstance().Warn("Ignoring bad frame", "err", err)
+				continue
+			}
+			if frame.Version != BroadcastVersion {
+				streamErr <- fmt.Errorf("broadcast version %d is not supported (expected %d)", frame.Version, BroadcastVersion)
+				return
+			}
+			frames <- frame
+		}
+		streamErr <- scanner.Err()
+	}()
+
+	renderer := GetRendererInstance()
+	spectator := NewHeadlessGame(0)
+	spectator.UI = NewInterface(renderer)
+	spectator.UI.Title = "SPECTATING"
+
+	eventHandler := NewEventHandler()
+	if err := eventHandler.Start(); err != nil {
+		return err
+	}
+	defer eventHandler.Stop()
+
+	for {
+		select {
+		case <-eventHandler.QuitChannel():
+			return nil
+		case <-eventHandler.InputEvents:
+			// Spectators cannot influence the game
+		case frame, ok := <-frames:
+			if !ok {
+				return <-streamErr
+			}
+			spectator.applyFrame(frame)
+			renderer.RenderGame(spectator)
+			renderer.Render()
+		case <-time.After(time.Second):
+			// Keep the screen fresh even if the game is idle
+			renderer.Render()
+		}
+	}
+}
+
+func (g *Game) applyFrame(frame SpectatorFrame) {
+	g.Mode = frame.Mode
+	g.placedBlocks = frame.Board
+	g.Player.CurrentPolymino = frame.Current
+	g.Player.NextPolyomino = frame.Next
+
+	scoring := frame.Scoring
+	g.Scoring = &scoring
+
+	g.Stats = restoredStatistics(frame.Stats)
+
+	g.timer.elapsed = frame.Elapsed
+	g.IsGameOver = frame.GameOver
+}
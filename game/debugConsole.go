@@ -0,0 +1,291 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	consoleHistory   = 6 // Command and result lines kept on screen
+	consolePieceRows = 3 // Rows for the active piece's blocks at most
+)
+
+// DebugConsole is the overlay opened with ` to inspect and poke at a running
+// game. The game is frozen while it is open, use step to move it along.
+type DebugConsole struct {
+	Active    bool
+	Input     []rune
+	Output    []string
+	FrameTime time.Duration // Time between the last two rendered frames
+
+	lastFrame time.Time
+}
+
+// ConsoleShapes are the named pieces for the spawn command
+var ConsoleShapes = map[string][]Position{
+	"I": {{0, 0}, {1, 0}, {2, 0}, {3, 0}},
+	"O": {{0, 0}, {1, 0}, {0, 1}, {1, 1}},
+	"T": {{0, 0}, {-1, 0}, {1, 0}, {0, 1}},
+	"S": {{0, 0}, {1, 0}, {-1, 1}, {0, 1}},
+	"Z": {{0, 0}, {-1, 0}, {0, 1}, {1, 1}},
+	"J": {{0, 0}, {-1, 0}, {1, 0}, {1, 1}},
+	"L": {{0, 0}, {-1, 0}, {1, 0}, {-1, 1}},
+}
+
+const consoleHelp = "spawn <shape|x,y ...> level <n> garbage <n> clear seed <n> step"
+
+// markFrame records the frame time, called whenever a frame is drawn
+func (c *DebugConsole) markFrame() {
+	now := time.Now()
+	if !c.lastFrame.IsZero() {
+		c.FrameTime = now.Sub(c.lastFrame)
+	}
+	c.lastFrame = now
+}
+
+func (c *DebugConsole) print(format string, args ...any) {
+	c.Output = append(c.Output, fmt.Sprintf(format, args...))
+	if len(c.Output) > consoleHistory {
+		c.Output = c.Output[len(c.Output)-consoleHistory:]
+	}
+}
+
+func (g *Game) toggleConsole() {
+	// Console commands would change one board of the match only
+	if g.Versus != nil && !g.console.Active {
+		GetLoggerInstance().Warn("The console is off during a versus match")
+		return
+	}
+
+	g.console.Active = !g.console.Active
+	g.console.Input = nil
+
+	// Freeze the clock so gravity does not catch up once the console closes
+	if g.Paused {
+		return
+	}
+	if g.console.Active {
+		g.timer.Pause()
+	} else {
+		g.timer.Resume()
+	}
+}
+
+func (g *Game) handleConsoleInput(event Event) {
+	switch event.Action {
+	case "console":
+		g.toggleConsole()
+	case "enter":
+		line := strings.TrimSpace(string(g.console.Input))
+		g.console.Input = nil
+		if line != "" {
+			g.console.print("> %s", line)
+			g.runConsoleCommand(line)
+		}
+	case "backspace":
+		if len(g.console.Input) > 0 {
+			g.console.Input = g.console.Input[:len(g.console.Input)-1]
+		}
+	case "space":
+		g.console.Input = append(g.console.Input, ' ')
+	default:
+		if event.Char >= ' ' && event.Char <= '~' {
+			g.console.Input = append(g.console.Input, event.Char)
+		}
+	}
+}
+
+func (g *Game) runConsoleCommand(line string) {
+	fields := strings.Fields(line)
+	command, args := strings.ToLower(fields[0]), fields[1:]
+
+	GetLoggerInstance().Debug("Console command", "command", line)
+
+	switch command {
+	case "help":
+		g.console.print("%s", consoleHelp)
+	case "spawn":
+		g.consoleSpawn(args)
+	case "level":
+		n, ok := g.consoleInt(args, 1)
+		if !ok {
+			return
+		}
+		g.Scoring.Level = n
+		g.Scoring.LinesCleared = (n - 1) * 10
+		g.console.print("level %d, drop every %dms", n, g.Scoring.GetDropSpeed())
+	case "garbage":
+		n, ok := g.consoleInt(args, 1)
+		if !ok {
+			return
+		}
+		if n > GameFieldHeight {
+			g.console.print("at most %d garbage rows", GameFieldHeight)
+			return
+		}
+		g.AddGarbageRows(n, g.rng.Intn(GameFieldWidth))
+		g.console.print("added %d garbage rows", n)
+	case "clear":
+		g.placedBlocks = nil
+		g.console.print("board cleared")
+	case "seed":
+		n, ok := g.consoleInt(args, 0)
+		if !ok {
+			return
+		}
+		g.ResetWithSeed(int64(n))
+		// ResetWithSeed restarts the clock, keep it frozen while the console is open
+		if !g.Paused {
+			g.timer.Pause()
+		}
+		g.console.print("new game with seed %d", n)
+	case "step":
+		if g.IsGameOver {
+			g.console.print("game is over")
+			return
+		}
+		g.timer.Advance(g.Scoring.GetDropSpeed())
+		g.drop(g.timer.elapsed)
+		g.console.print("stepped to %dms", g.timer.elapsed)
+	default:
+		g.console.print("unknown command %q, try help", command)
+	}
+}
+
+// consoleInt parses the single integer argument of a command
+func (g *Game) consoleInt(args []string, minimum int) (int, bool) {
+	if len(args) != 1 {
+		g.console.print("expected one number")
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < minimum {
+		g.console.print("invalid number %q", args[0])
+		return 0, false
+	}
+	return n, true
+}
+
+// consoleSpawn replaces the active piece with a named shape or a list of
+// block offsets like "0,0 1,0 1,1"
+func (g *Game) consoleSpawn(args []string) {
+	if len(args) == 0 {
+		names := make([]string, 0, len(ConsoleShapes))
+		for name := range ConsoleShapes {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		g.console.print("spawn %s or x,y ...", strings.Join(names, "|"))
+		return
+	}
+
+	var cells []Position
+	if shape, ok := ConsoleShapes[strings.ToUpper(args[0])]; ok && len(args) == 1 {
+		cells = append(cells, shape...)
+	} else {
+		for _, arg := range args {
+			var cell Position
+			if _, err := fmt.Sscanf(arg, "%d,%d", &cell.X, &cell.Y); err != nil {
+				g.console.print("invalid block %q", arg)
+				return
+			}
+			cells = append(cells, cell)
+		}
+	}
+	if err := checkShape(cells); err != nil {
+		g.console.print("%v", err)
+		return
+	}
+
+	color := "white"
+	if g.Player.CurrentPolymino != nil {
+		color = pieceColor(g.Player.CurrentPolymino)
+	}
+
+	g.Player.CurrentPolymino = NewSpawnPolyomino(cells, color)
+	g.Player.HasSwapped = false
+	g.console.print("spawned %d blocks at %d,%d", len(cells),
+		g.Player.CurrentPolymino.Position.X, g.Player.CurrentPolymino.Position.Y)
+}
+
+// DrawConsole covers the field with the console: frame time, the active
+// piece's internals, recent log lines, command results and the prompt.
+func (ui *Interface) DrawConsole(game *Game) {
+	consoleX := ui.view.FieldX
+	consoleY := ui.view.FieldY
+	consoleWidth := GameFieldWidth * BlockWidth
+
+	lines := []string{
+		fmt.Sprintf("CONSOLE  frame %dms", game.console.FrameTime.Milliseconds()),
+		fmt.Sprintf("t=%dms drop=%dms", game.timer.elapsed, game.Scoring.GetDropSpeed()),
+	}
+
+	if piece := game.Player.CurrentPolymino; piece != nil {
+		lines = append(lines,
+			fmt.Sprintf("pos %d,%d rot %d,%d", piece.Position.X, piece.Position.Y,
+				piece.RotationPoint.X, piece.RotationPoint.Y),
+		)
+		// A long block list ends in "..." so the log keeps its rows
+		blocks := "blk"
+		rows := 1
+		for i, block := range piece.Blocks {
+			cell := fmt.Sprintf(" %d,%d", block.Position.X, block.Position.Y)
+			limit := consoleWidth
+			if rows == consolePieceRows && i < len(piece.Blocks)-1 {
+				limit -= len(" ...")
+			}
+			if len(blocks)+len(cell) > limit {
+				if rows == consolePieceRows {
+					blocks += " ..."
+					break
+				}
+				lines = append(lines, blocks)
+				blocks = "   "
+				rows++
+			}
+			blocks += cell
+		}
+		lines = append(lines, blocks)
+	} else {
+		lines = append(lines, "no active piece")
+	}
+
+	lines = append(lines, "", "LOG")
+	promptRows := len(game.console.Output) + 2
+	logRows := GameFieldHeight - len(lines) - promptRows
+	lines = append(lines, GetLoggerInstance().screen.Last(logRows)...)
+	for len(lines) < GameFieldHeight-promptRows {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, game.console.Output...)
+	lines = append(lines, "> "+string(game.console.Input)+"_")
+
+	for row, line := range lines {
+		if row >= GameFieldHeight {
+			break
+		}
+
+		color := "white"
+		switch {
+		case row == 0:
+			color = "yellow"
+		case row < GameFieldHeight-promptRows:
+			color = "cyan"
+		}
+
+		runes := []rune(line)
+		for x := 0; x < consoleWidth; x++ {
+			pixel := ColoredPixel{Char: ' ', Color: ""}
+			if x < len(runes) {
+				pixel = ColoredPixel{Char: runes[x], Color: color}
+			}
+			ui.renderer.Pixels[consoleY+row][consoleX+x] = pixel
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package game
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestConsoleGarbage(t *testing.T) {
+	tests := []struct {
+		command string
+		want    int // Garbage rows on the board afterwards
+	}{
+		{"garbage 3", 3},
+		{"garbage 25", GameFieldHeight},
+		{"garbage 26", 0},
+		{"garbage 1000000000", 0},
+		{"garbage 0", 0},
+		{"garbage x", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			g := NewHeadlessGame(1)
+			g.runConsoleCommand(tt.command)
+
+			if got := countColor(g.placedBlocks, GarbageColor) / (GameFieldWidth - 1); got != tt.want {
+				t.Errorf("%d garbage rows, want %d (%s)", got, tt.want, strings.Join(g.console.Output, "; "))
+			}
+		})
+	}
+}
+
+func TestConsoleSpawn(t *testing.T) {
+	tests := []struct {
+		command string
+		want    int // Blocks of the active piece afterwards, 0 if it was refused
+	}{
+		{"spawn T", 4},
+		{"spawn 0,0 1,0 1,1", 3},
+		{"spawn 0,0", 1},
+		{"spawn 0,0 2,0", 0},
+		{"spawn 0,0 1,0 0,0", 0},
+		{"spawn 0,0 1,0 2,0 3,0 4,0 5,0 6,0 7,0 8,0 9,0 10,0", 0},
+		{"spawn 0,0 x", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			g := NewHeadlessGame(1)
+			before := g.Player.CurrentPolymino
+
+			g.runConsoleCommand(tt.command)
+
+			switch {
+			case tt.want == 0 && g.Player.CurrentPolymino != before:
+				t.Errorf("spawned a piece, want it refused")
+			case tt.want > 0 && len(g.Player.CurrentPolymino.Blocks) != tt.want:
+				t.Errorf("spawned %d blocks, want %d (%s)", len(g.Player.CurrentPolymino.Blocks), tt.want,
+					strings.Join(g.console.Output, "; "))
+			}
+		})
+	}
+}
+
+func TestRingBufferLast(t *testing.T) {
+	buffer := &ringBuffer{size: 3}
+	buffer.Write([]byte("a\nb\nc\nd\n"))
+
+	tests := []struct {
+		n    int
+		want []string
+	}{
+		{2, []string{"c", "d"}},
+		{3, []string{"b", "c", "d"}},
+		{10, []string{"b", "c", "d"}},
+		{0, []string{}},
+		{-4, []string{}},
+	}
+
+	for _, tt := range tests {
+		if got := buffer.Last(tt.n); !slices.Equal(got, tt.want) {
+			t.Errorf("Last(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
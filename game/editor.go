@@ -0,0 +1,436 @@
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// editorBrushes are the board cells the editor paints, in the puzzle file's
+// notation
+const editorBrushes = "brgycmw#"
+
+var editorHelp = []string{
+	"arrows  move",
+	"space   paint",
+	"x       erase",
+	"brgycmw# brush",
+	"IOTSZJL add piece",
+	"n draw  enter add",
+	"u  remove last",
+	"q goal  +- count",
+	"v gravity",
+	"tab test F5 save",
+}
+
+// Editor builds puzzles on the game field: paint the board, fill the queue,
+// pick the goal, try it out and save it in the puzzle format.
+type Editor struct {
+	Puzzle Puzzle // Its Board is rebuilt from the painted cells on test and save
+	Path   string
+
+	cells   map[Position]rune // Painted cells in puzzle notation
+	cursor  Position          // Field cell under the cursor
+	brush   rune
+	drawing map[Position]bool // Cells of a piece being drawn, nil when not drawing
+	message string
+
+	ui           *Interface
+	eventHandler *EventHandler
+	test         *Game // The puzzle being played from the editor, nil while editing
+}
+
+// NewEditor edits puzzle and saves it to path
+func NewEditor(puzzle Puzzle, path string) *Editor {
+	e := &Editor{
+		Puzzle:       puzzle,
+		Path:         path,
+		cells:        map[Position]rune{},
+		cursor:       Position{X: GameFieldWidth / 2, Y: GameFieldHeight - 1},
+		brush:        rune(editorBrushes[0]),
+		ui:           NewInterface(GetRendererInstance()),
+		eventHandler: NewEventHandler(),
+	}
+
+	top := GameFieldHeight - len(puzzle.Board)
+	for y, row := range puzzle.Board {
+		for x, c := range []rune(row) {
+			if _, ok := puzzleColors[c]; ok {
+				e.cells[Position{X: x, Y: top + y}] = c
+			}
+		}
+	}
+
+	if e.Puzzle.Name == "" {
+		e.Puzzle.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if e.Puzzle.Goal.Type == "" {
+		e.Puzzle.Goal.Type = GoalClearAll
+	}
+	return e
+}
+
+func (e *Editor) Start() error {
+	renderer := GetRendererInstance()
+	if err := e.eventHandler.Start(); err != nil {
+		return err
+	}
+
+	defer e.eventHandler.Stop()
+
+	ticker := time.NewTicker(stepInterval)
+	defer ticker.Stop()
+
+	clock := newStepClock()
+	var frames frameLimiter
+
+	running := true
+	for running {
+		select {
+		case <-e.eventHandler.QuitChannel():
+			running = false
+		case <-ticker.C:
+			for steps := clock.due(); steps > 0; steps-- {
+				e.handleInputs()
+				if e.test != nil {
+					e.test.Update()
+				}
+			}
+
+			e.render(renderer)
+			frames.present(renderer)
+		}
+	}
+	return nil
+}
+
+func (e *Editor) handleInputs() {
+	for {
+		select {
+		case event := <-e.eventHandler.InputEvents:
+			e.handleInput(event)
+		default:
+			return
+		}
+	}
+}
+
+func (e *Editor) handleInput(event Event) {
+	// Tab switches between editing and playing the puzzle
+	if event.Action == "stats" {
+		e.toggleTest()
+		return
+	}
+
+	if e.test != nil {
+		e.test.handleInput(event)
+		return
+	}
+
+	e.message = ""
+	switch event.Action {
+	case "left":
+		e.moveCursor(-1, 0)
+	case "right":
+		e.moveCursor(1, 0)
+	case "up":
+		e.moveCursor(0, -1)
+	case "down":
+		e.moveCursor(0, 1)
+	case "space":
+		e.paint()
+	case "backspace":
+		e.erase()
+	case "enter":
+		e.finishDrawing()
+	case "save":
+		e.save()
+	default:
+		// Letters go by the character, whatever they are bound to in the game
+		if event.Char != 0 {
+			e.handleChar(event.Char)
+		}
+	}
+}
+
+func (e *Editor) handleChar(c rune) {
+	switch {
+	case strings.ContainsRune(editorBrushes, c):
+		e.brush = c
+	case strings.ContainsRune("IOTSZJL", c):
+		e.addPiece(PuzzlePiece{Shape: string(c), Color: puzzleColors[e.brush]})
+	case c == 'x':
+		e.erase()
+	case c == 'n':
+		if e.drawing == nil {
+			e.drawing = map[Position]bool{}
+			e.message = "space marks the cells of the piece, enter adds it"
+		} else {
+			e.drawing = nil
+		}
+	case c == 'u':
+		if len(e.Puzzle.Queue) > 0 {
+			e.Puzzle.Queue = e.Puzzle.Queue[:len(e.Puzzle.Queue)-1]
+		}
+	case c == 'q':
+		e.Puzzle.Goal.Type = nextOf([]string{GoalClearAll, GoalClearLines, GoalSurvive}, e.Puzzle.Goal.Type)
+		if e.Puzzle.Goal.Type != GoalClearAll && e.Puzzle.Goal.Count == 0 {
+			e.Puzzle.Goal.Count = 1
+		}
+	case c == '+' || c == '=':
+		e.Puzzle.Goal.Count++
+	case c == '-':
+		e.Puzzle.Goal.Count = max(e.Puzzle.Goal.Count-1, 1)
+	case c == 'v':
+		gravity := e.Puzzle.Gravity
+		if gravity == "" {
+			gravity = GravityNaive
+		}
+		e.Puzzle.Gravity = nextOf(GravityModes, gravity)
+	}
+}
+
+// nextOf returns the value after current in values, wrapping around
+func nextOf(values []string, current string) string {
+	for i, value := range values {
+		if value == current {
+			return values[(i+1)%len(values)]
+		}
+	}
+	return values[0]
+}
+
+// moveCursor steps the cursor in screen space and maps it back to the field,
+// so it stops at the field's edges wherever the layout put the field
+func (e *Editor) moveCursor(dx, dy int) {
+	r := e.ui.renderer
+	r.view = e.ui.view
+
+	screenX, screenY := r.GameToScreenCoordinates(e.cursor.X, e.cursor.Y)
+	screenX += dx * BlockWidth
+	screenY += dy
+	if r.IsInGameArea(screenX, screenY) {
+		e.cursor.X, e.cursor.Y = r.ScreenToGameCoordinates(screenX, screenY)
+	}
+}
+
+func (e *Editor) paint() {
+	if e.drawing != nil {
+		if e.drawing[e.cursor] {
+			delete(e.drawing, e.cursor)
+		} else {
+			e.drawing[e.cursor] = true
+		}
+		return
+	}
+	e.cells[e.cursor] = e.brush
+}
+
+func (e *Editor) erase() {
+	if e.drawing != nil {
+		delete(e.drawing, e.cursor)
+		return
+	}
+	delete(e.cells, e.cursor)
+}
+
+func (e *Editor) addPiece(piece PuzzlePiece) {
+	e.Puzzle.Queue = append(e.Puzzle.Queue, piece)
+	e.message = fmt.Sprintf("added piece %d", len(e.Puzzle.Queue))
+}
+
+// finishDrawing adds the drawn cells to the queue as a piece that rotates
+// around its middle
+func (e *Editor) finishDrawing() {
+	if len(e.drawing) == 0 {
+		e.message = "mark cells with space first, n to start drawing"
+		return
+	}
+
+	blocks := make([]Block, 0, len(e.drawing))
+	drawn := make([]Position, 0, len(e.drawing))
+	for cell := range e.drawing {
+		blocks = append(blocks, Block{Position: cell})
+		drawn = append(drawn, cell)
+	}
+	// The same checks as loading the file, so a saved puzzle always loads
+	if err := checkShape(drawn); err != nil {
+		e.message = "cannot add the piece, " + err.Error()
+		return
+	}
+
+	sort.Slice(blocks, func(i, j int) bool {
+		if blocks[i].Y != blocks[j].Y {
+			return blocks[i].Y < blocks[j].Y
+		}
+		return blocks[i].X < blocks[j].X
+	})
+
+	origin := CalculateRotationPoint(blocks)
+	cells := make([]string, len(blocks))
+	for i, block := range blocks {
+		cells[i] = fmt.Sprintf("%d,%d", block.X-origin.X, block.Y-origin.Y)
+	}
+
+	e.addPiece(PuzzlePiece{Blocks: strings.Join(cells, " "), Color: puzzleColors[e.brush]})
+	e.drawing = nil
+}
+
+// puzzle returns the puzzle as edited so far
+func (e *Editor) puzzle() Puzzle {
+	puzzle := e.Puzzle
+
+	top := GameFieldHeight
+	for cell := range e.cells {
+		top = min(top, cell.Y)
+	}
+
+	puzzle.Board = []string{}
+	for y := top; y < GameFieldHeight; y++ {
+		row := []rune(strings.Repeat(".", GameFieldWidth))
+		for x := range row {
+			if c, ok := e.cells[Position{X: x, Y: y}]; ok {
+				row[x] = c
+			}
+		}
+		puzzle.Board = append(puzzle.Board, string(row))
+	}
+	return puzzle
+}
+
+func (e *Editor) toggleTest() {
+	if e.test != nil {
+		e.test = nil
+		return
+	}
+
+	puzzle := e.puzzle()
+	if err := puzzle.Validate(); err != nil {
+		e.message = "cannot test: " + err.Error()
+		return
+	}
+	e.test = NewPuzzleGame(puzzle)
+}
+
+func (e *Editor) save() {
+	puzzle := e.puzzle()
+	if err := puzzle.Validate(); err != nil {
+		e.message = "cannot save: " + err.Error()
+		return
+	}
+
+	if err := WritePuzzle(e.Path, puzzle); err != nil {
+		e.message = "cannot save: " + err.Error()
+		GetLoggerInstance().Error("Could not save puzzle", "path", e.Path, "err", err)
+		return
+	}
+	e.message = "saved " + e.Path
+	GetLoggerInstance().Info("Puzzle saved", "path", e.Path)
+}
+
+func (e *Editor) render(r *Renderer) {
+	if e.test != nil {
+		r.RenderFrame(e.test)
+		return
+	}
+
+	r.fit(1)
+	r.Clear()
+	if r.layout.TooSmall {
+		r.drawEnlargeMessage()
+		return
+	}
+
+	r.BuildBorder()
+	e.ui.place(r.layout, 0)
+	r.view = e.ui.view
+
+	for cell, c := range e.cells {
+		r.RenderBlock(Block{Position: cell, Color: puzzleColors[c]}, 0, 0)
+	}
+	for cell := range e.drawing {
+		screenX, screenY := r.GameToScreenCoordinates(cell.X, cell.Y)
+		r.Pixels[screenY][screenX] = ColoredPixel{Char: r.glyphs.Ghost[0], Color: piecePixel + puzzleColors[e.brush]}
+		r.Pixels[screenY][screenX+1] = ColoredPixel{Char: r.glyphs.Ghost[1], Color: piecePixel + puzzleColors[e.brush]}
+	}
+
+	screenX, screenY := r.GameToScreenCoordinates(e.cursor.X, e.cursor.Y)
+	r.Pixels[screenY][screenX] = ColoredPixel{Char: '[', Color: "yellow"}
+	r.Pixels[screenY][screenX+1] = ColoredPixel{Char: ']', Color: "yellow"}
+
+	e.drawMessage(r)
+	e.drawPanel()
+}
+
+// drawMessage wraps the last message over the top rows of the field
+func (e *Editor) drawMessage(r *Renderer) {
+	width := GameFieldWidth * BlockWidth
+	line := ""
+	row := 0
+	flush := func() {
+		for i, char := range []rune(line) {
+			r.Pixels[e.ui.view.FieldY+row][e.ui.view.FieldX+i] = ColoredPixel{Char: char, Color: "yellow"}
+		}
+		line = ""
+		row++
+	}
+
+	for _, word := range strings.Fields(e.message) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			flush()
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+		if len([]rune(line)) > width {
+			line = string([]rune(line)[:width])
+		}
+	}
+	if line != "" {
+		flush()
+	}
+}
+
+func (e *Editor) drawPanel() {
+	ui := e.ui
+	ui.Clear()
+	ui.DrawSeparators()
+
+	ui.DrawLabel("BRUSH", 0, "white")
+	if e.brush == '#' {
+		ui.DrawLabel("garbage", 1, piecePixel+GarbageColor)
+	} else {
+		ui.DrawLabel(puzzleColors[e.brush], 1, piecePixel+puzzleColors[e.brush])
+	}
+
+	goal := e.Puzzle.Goal
+	ui.DrawLabel("GOAL", 3, "white")
+	switch goal.Type {
+	case GoalClearLines:
+		ui.DrawLabel(fmt.Sprintf("LINES %d", goal.Count), 4, "green")
+	case GoalSurvive:
+		ui.DrawLabel(fmt.Sprintf("PIECES %d", goal.Count), 4, "green")
+	default:
+		ui.DrawLabel("CLEAR ALL", 4, "green")
+	}
+
+	gravity := e.Puzzle.Gravity
+	if gravity == "" {
+		gravity = GravityNaive
+	}
+	ui.DrawLabel("GRAVITY", 6, "white")
+	ui.DrawLabel(gravity, 7, "cyan")
+
+	ui.DrawLabel("QUEUE", 9, "white")
+	ui.DrawLabel(fmt.Sprintf("%d pieces", len(e.Puzzle.Queue)), 10, "yellow")
+
+	ui.DrawLabel("CURSOR", 12, "white")
+	ui.DrawLabel(fmt.Sprintf("%d,%d", e.cursor.X, e.cursor.Y), 13, "magenta")
+
+	for i, line := range editorHelp {
+		ui.DrawLabel(line, ui.separators[len(ui.separators)-1]+1+i, "white")
+	}
+}
@@ -1,84 +1,174 @@
 package game
 
 import (
-	"log"
+	"fmt"
+	"unicode"
 
 	"github.com/eiannone/keyboard"
 )
 
+// InputSource is where a game gets its key presses from: the keyboard, or a
+// script in automated runs. Stop is called once the game loop ends.
+type InputSource interface {
+	Start() error
+	Events() <-chan Event
+	QuitChannel() <-chan bool
+	Stop()
+}
+
 type EventHandler struct {
 	Quit        chan bool
 	InputEvents chan Event
+	Keymap      Keymap
+	Mouse       bool   // Also report mouse presses, needs a unix terminal
+	restore     func() // Puts the terminal back after startTerminal
 }
 
 type Event struct {
 	Action    string
+	Char      rune // Printable character behind the action, used for text entry
+	Player    int  // Index of the player the key belongs to in split screen
+	X, Y      int  // Screen cell of a mouse click, counted from 0
+	Bot       bool // Sent by the autoplay bot rather than a key
 	Timestamp int64
 }
 
+// KeyBinding is the action a key triggers and for which player
+type KeyBinding struct {
+	Action string
+	Player int
+}
+
+// Keymap translates special keys and (case-insensitive) characters to actions
+type Keymap struct {
+	Keys  map[keyboard.Key]KeyBinding
+	Chars map[rune]KeyBinding
+}
+
+func DefaultKeymap() Keymap {
+	return Keymap{
+		Keys: map[keyboard.Key]KeyBinding{
+			keyboard.KeyArrowDown:  {Action: "down"},
+			keyboard.KeyArrowUp:    {Action: "up"},
+			keyboard.KeyArrowLeft:  {Action: "left"},
+			keyboard.KeyArrowRight: {Action: "right"},
+			keyboard.KeySpace:      {Action: "space"},
+			keyboard.KeyF5:         {Action: "save"},
+			keyboard.KeyF9:         {Action: "load"},
+			keyboard.KeyTab:        {Action: "stats"},
+			keyboard.KeyF2:         {Action: "logLevel"},
+			keyboard.KeyEnter:      {Action: "enter"},
+			keyboard.KeyBackspace:  {Action: "backspace"},
+			keyboard.KeyBackspace2: {Action: "backspace"},
+		},
+		Chars: map[rune]KeyBinding{
+			'c': {Action: "swap"},
+			'd': {Action: "hardDrop"},
+			'r': {Action: "restart"},
+			'p': {Action: "pause"},
+			'l': {Action: "logs"},
+			'`': {Action: "console"},
+		},
+	}
+}
+
+// SplitScreenKeymap gives the left player WASD and the right player the arrows
+func SplitScreenKeymap() Keymap {
+	return Keymap{
+		Keys: map[keyboard.Key]KeyBinding{
+			keyboard.KeySpace:      {Action: "hardDrop", Player: 0},
+			keyboard.KeyArrowUp:    {Action: "up", Player: 1},
+			keyboard.KeyArrowDown:  {Action: "down", Player: 1},
+			keyboard.KeyArrowLeft:  {Action: "left", Player: 1},
+			keyboard.KeyArrowRight: {Action: "right", Player: 1},
+			keyboard.KeyEnter:      {Action: "hardDrop", Player: 1},
+		},
+		Chars: map[rune]KeyBinding{
+			'w': {Action: "up", Player: 0},
+			'a': {Action: "left", Player: 0},
+			's': {Action: "down", Player: 0},
+			'd': {Action: "right", Player: 0},
+			'q': {Action: "swap", Player: 0},
+			'/': {Action: "swap", Player: 1},
+			'r': {Action: "restart"},
+			'p': {Action: "pause"},
+		},
+	}
+}
+
 var logger = GetLoggerInstance()
 
 func NewEventHandler() *EventHandler {
 	return &EventHandler{
 		Quit:        make(chan bool),
 		InputEvents: make(chan Event),
+		Keymap:      DefaultKeymap(),
 	}
 }
 
-func (e *EventHandler) Start() {
-	err := keyboard.Open()
-	if err != nil {
-		log.Fatal(err)
+// Start reads the keyboard until Esc is pressed. It fails when there is no
+// terminal to read from.
+func (e *EventHandler) Start() error {
+	if e.Mouse {
+		err := e.startTerminal()
+		if err == nil {
+			return nil
+		}
+		logger.Warn("Mouse input unavailable, using the keyboard only", "err", err)
+	}
+
+	if err := keyboard.Open(); err != nil {
+		return fmt.Errorf("opening keyboard: %w", err)
 	}
 
 	go func() {
 		for {
 			char, key, err := keyboard.GetKey()
 			if err != nil {
-				logger.Log("Error reading key: " + err.Error())
+				logger.Error("Error reading key", "err", err)
 				continue
 			}
 
-			switch key {
-			case keyboard.KeyEsc:
+			if key == keyboard.KeyEsc {
 				e.Quit <- true
 				keyboard.Close()
 				return
-			case keyboard.KeyArrowDown:
-				logger.Log("Key pressed: Down Arrow")
-				e.InputEvents <- Event{Action: "down", Timestamp: 0}
-			case keyboard.KeyArrowUp:
-				logger.Log("Key pressed: Up Arrow")
-				e.InputEvents <- Event{Action: "up"}
-			case keyboard.KeyArrowLeft:
-				logger.Log("Key pressed: Left Arrow")
-				e.InputEvents <- Event{Action: "left"}
-			case keyboard.KeyArrowRight:
-				logger.Log("Key pressed: Right Arrow")
-				e.InputEvents <- Event{Action: "right"}
-			case keyboard.KeySpace:
-				logger.Log("Key pressed: Space")
-				e.InputEvents <- Event{Action: "space"}
-			default:
-				if char == 'c' || char == 'C' {
-					logger.Log("Key pressed: C - Swap blocks")
-					e.InputEvents <- Event{Action: "swap"}
-				} else if char == 'd' || char == 'D' {
-					logger.Log("Key pressed: D - Hard drop")
-					e.InputEvents <- Event{Action: "hardDrop"}
-				} else if char == 'r' || char == 'R' {
-					logger.Log("Key pressed: R - Restart game")
-					e.InputEvents <- Event{Action: "restart"}
-				} else {
-					log.Printf("Key pressed: %c", char)
-				}
+			}
+
+			if event, ok := e.Keymap.Translate(char, key); ok {
+				logger.Debug("Key pressed", "action", event.Action)
+				e.InputEvents <- event
 			}
 		}
 	}()
+
+	return nil
+}
+
+// Translate maps a key press to an event. Unbound printable characters still
+// produce a "char" event so they can be typed into prompts.
+func (k Keymap) Translate(char rune, key keyboard.Key) (Event, bool) {
+	if char == 0 {
+		binding, ok := k.Keys[key]
+		return Event{Action: binding.Action, Player: binding.Player}, ok
+	}
+
+	if binding, ok := k.Chars[unicode.ToLower(char)]; ok {
+		return Event{Action: binding.Action, Char: char, Player: binding.Player}, true
+	}
+
+	return Event{Action: "char", Char: char}, true
 }
 
 func (e *EventHandler) Stop() {
 	close(e.Quit)
+	if e.restore != nil {
+		e.restore()
+	}
+}
+
+func (e *EventHandler) Events() <-chan Event {
+	return e.InputEvents
 }
 
 func (e *EventHandler) QuitChannel() <-chan bool {
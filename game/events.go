@@ -0,0 +1,201 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GameEvent is a typed notification about something that happened in a game.
+// Every event carries At, the game time in milliseconds when it happened.
+type GameEvent interface {
+	EventType() string
+}
+
+type PieceSpawned struct {
+	At       int64    `json:"at"`
+	Size     int      `json:"size"`
+	Color    string   `json:"color"`
+	Position Position `json:"position"`
+}
+
+// PieceMoved is published for every successful move or rotation. Action is
+// the processInput action, or "gravity" for automatic drops.
+type PieceMoved struct {
+	At       int64    `json:"at"`
+	Action   string   `json:"action"`
+	Position Position `json:"position"`
+}
+
+type PieceLocked struct {
+	At          int64      `json:"at"`
+	PieceID     int        `json:"pieceId"` // Also stored on the locked blocks
+	Shape       string     `json:"shape"`   // ShapeID of the piece
+	Size        int        `json:"size"`
+	Color       string     `json:"color"`
+	Cells       []Position `json:"cells"` // Absolute field positions of the locked blocks
+	StackHeight int        `json:"stackHeight"`
+}
+
+type LinesCleared struct {
+	At         int64 `json:"at"`
+	Lines      int   `json:"lines"`
+	Rows       []int `json:"rows"`  // Field rows that were full, top to bottom
+	Chain      int   `json:"chain"` // 1 for the lock's own clear, 2 and up for the chain after it
+	TotalLines int   `json:"totalLines"`
+	Score      int   `json:"score"`
+
+	Stack []Block `json:"-"` // The stack just before the rows were removed
+}
+
+// HardDropped is published before the dropped piece locks. Cells are where
+// it landed, Distance is how many rows it fell.
+type HardDropped struct {
+	At       int64      `json:"at"`
+	Distance int        `json:"distance"`
+	Color    string     `json:"color"`
+	Cells    []Position `json:"cells"`
+}
+
+type LevelUp struct {
+	At    int64 `json:"at"`
+	Level int   `json:"level"`
+}
+
+type HoldUsed struct {
+	At    int64  `json:"at"`
+	Size  int    `json:"size"` // Size of the piece that is now active
+	Color string `json:"color"`
+}
+
+type GameOver struct {
+	At    int64 `json:"at"`
+	Score int   `json:"score"`
+	Lines int   `json:"lines"`
+	Level int   `json:"level"`
+}
+
+func (PieceSpawned) EventType() string { return "PieceSpawned" }
+func (PieceMoved) EventType() string   { return "PieceMoved" }
+func (PieceLocked) EventType() string  { return "PieceLocked" }
+func (LinesCleared) EventType() string { return "LinesCleared" }
+func (HardDropped) EventType() string  { return "HardDropped" }
+func (LevelUp) EventType() string      { return "LevelUp" }
+func (HoldUsed) EventType() string     { return "HoldUsed" }
+func (GameOver) EventType() string     { return "GameOver" }
+
+// EventBus delivers game events synchronously, in subscription order, on the
+// goroutine that runs the game.
+type EventBus struct {
+	mu          sync.RWMutex
+	subscribers []func(GameEvent)
+}
+
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+func (b *EventBus) Subscribe(handler func(GameEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.subscribers = append(b.subscribers, handler)
+}
+
+func (b *EventBus) Publish(event GameEvent) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+
+	for _, handler := range subscribers {
+		handler(event)
+	}
+}
+
+// On subscribes a handler to a single event type, e.g.
+//
+//	On(bus, func(e LinesCleared) { ... })
+func On[T GameEvent](bus *EventBus, handler func(T)) {
+	bus.Subscribe(func(event GameEvent) {
+		if typed, ok := event.(T); ok {
+			handler(typed)
+		}
+	})
+}
+
+// newLoggedEventBus returns a bus that already writes the notable events to
+// the game log
+func newLoggedEventBus() *EventBus {
+	bus := NewEventBus()
+	bus.Subscribe(logEvent)
+	return bus
+}
+
+func logEvent(event GameEvent) {
+	switch e := event.(type) {
+	case LinesCleared:
+		GetLoggerInstance().Info("Cleared lines", "lines", e.Lines, "score", e.Score)
+	case LevelUp:
+		GetLoggerInstance().Info("Level up", "level", e.Level)
+	case HoldUsed:
+		GetLoggerInstance().Debug("Blocks swapped successfully")
+	case GameOver:
+		GetLoggerInstance().Info("GAME OVER!", "score", e.Score, "lines", e.Lines, "level", e.Level)
+	}
+}
+
+// eventLine is the JSON-lines representation of an event
+type eventLine struct {
+	Type  string    `json:"type"`
+	Event GameEvent `json:"event"`
+}
+
+// JSONLinesSink returns a subscriber that writes one JSON object per event
+func JSONLinesSink(w io.Writer) func(GameEvent) {
+	var mu sync.Mutex
+	encoder := json.NewEncoder(w)
+
+	return func(event GameEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if err := encoder.Encode(eventLine{Type: event.EventType(), Event: event}); err != nil {
+			GetLoggerInstance().Error("Could not write event", "err", err)
+		}
+	}
+}
+
+// OpenEventSink opens the target of the JSON-lines event stream: a file path
+// (appended to) or "fd:N" for an already open file descriptor.
+func OpenEventSink(target string) (io.WriteCloser, error) {
+	if fd, ok := strings.CutPrefix(target, "fd:"); ok {
+		n, err := strconv.Atoi(fd)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid file descriptor %q", fd)
+		}
+		return os.NewFile(uintptr(n), "events-fd-"+fd), nil
+	}
+
+	return os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
+
+func (g *Game) publish(event GameEvent) {
+	if g.Events != nil {
+		g.Events.Publish(event)
+	}
+}
+
+func absoluteCells(polyomino *Polyomino) []Position {
+	cells := make([]Position, len(polyomino.Blocks))
+	for i, block := range polyomino.Blocks {
+		cells[i] = Position{
+			X: polyomino.Position.X + block.Position.X,
+			Y: polyomino.Position.Y + block.Position.Y,
+		}
+	}
+	return cells
+}
@@ -14,78 +14,256 @@ const (
 	GameFieldEndY   = GameFieldStartY + GameFieldHeight
 
 	BlockWidth = 2
+
+	ModeMarathon   = "marathon"
+	ModeSimulation = "simulation"
+	ModeVersus     = "versus"
+	ModeSplit      = "split"
 )
 
 type Game struct {
-	timer        *GameTimer
-	eventHandler *EventHandler
-	Player       *Player
-	placedBlocks []Block
-	lastDropTime int64
-	Scoring      *ScoringSystem
-	UI           *Interface
-	IsGameOver   bool // Flag to indicate if the game is over
+	timer         *GameTimer
+	Input         InputSource // Key presses, from the keyboard unless replaced before Start
+	Player        *Player
+	placedBlocks  []Block
+	lastDropTime  int64
+	Scoring       *ScoringSystem
+	UI            *Interface
+	IsGameOver    bool // Flag to indicate if the game is over
+	Mode          string
+	Gravity       string // GravityNaive, GravitySticky or GravityCascade
+	Seed          int64
+	rng           *Randomizer
+	HighScores    *HighScoreTable
+	Stats         *Statistics
+	Bot           *Bot // Plays the game through a BotInput when set
+	Versus        *VersusSession
+	Puzzle        *PuzzleRun    // Set in puzzle mode, pieces then come from its queue
+	PieceSet      *PieceSet     // Pieces to draw from, random polyominoes when nil
+	PieceSizes    PieceSizes    // How big the random polyominoes get
+	Garbage       GarbageQueue  // Rows sent by the opponent, added after the next lock
+	GarbageTarget GarbageTarget // Where our clears send garbage, nil outside two-player modes
+	Broadcast     *BroadcastServer
+	API           *APIServer
+	Events        *EventBus // Outlives Reset, so subscribers follow every round
+	Paused        bool
+	nameEntry     NameEntry
+	console       DebugConsole
+
+	lastAutosave  int64
+	pendingResume *SaveState // Autosave waiting for the player to accept or discard it
 }
 
 func NewGame() *Game {
 	renderer := GetRendererInstance()
 
+	g := NewHeadlessGame(NewSeed())
+	g.Input = NewEventHandler()
+	g.UI = NewInterface(renderer)
+	g.UI.Animations.Subscribe(g.Events)
+	g.Mode = ModeMarathon
+	g.HighScores = LoadHighScores(HighScoresPath())
+
+	return g
+}
+
+// NewHeadlessGame creates a game without renderer, keyboard or persistence.
+// It is advanced with Advance instead of Start, so many can run in parallel.
+func NewHeadlessGame(seed int64) *Game {
+	rng := NewRandomizer(seed)
+	sizes := DefaultPieceSizes()
+
 	return &Game{
-		timer:        NewGameTimer(),
-		eventHandler: NewEventHandler(),
-		Player:       NewPlayer(),
-		Scoring:      NewScoringSystem(),
-		UI:           NewInterface(renderer),
+		timer:      NewGameTimer(),
+		Player:     NewPlayer(GeneratePolyomino(rng, sizes.Min, sizes.Max)),
+		Scoring:    NewScoringSystem(),
+		Mode:       ModeSimulation,
+		Gravity:    GravityNaive,
+		PieceSizes: sizes,
+		Seed:       seed,
+		rng:        rng,
+		Stats:      NewStatistics(),
+		Events:     newLoggedEventBus(),
 	}
 }
 
-func (g *Game) Start() {
+// Start runs the game until the player quits. It fails when the input
+// cannot be read, e.g. without a keyboard device.
+func (g *Game) Start() error {
 	renderer := GetRendererInstance()
-	g.eventHandler.Start()
+	if err := g.Input.Start(); err != nil {
+		return err
+	}
 	g.timer.Reset()
+	g.checkForResume()
+
+	defer g.Input.Stop()
+
+	ticker := time.NewTicker(stepInterval)
+	defer ticker.Stop()
 
-	defer g.eventHandler.Stop()
+	clock := newStepClock()
+	var frames frameLimiter
 
 	running := true
 	for running {
-		g.Update()
-
-		if g.IsGameOver {
-			select {
-			case <-g.eventHandler.QuitChannel():
-				running = false
-			case event := <-g.eventHandler.InputEvents:
-				if event.Action == "restart" {
-					g.Reset()
-				} else if event.Action == "quit" {
+		select {
+		case <-g.Input.QuitChannel():
+			running = false
+		case <-ticker.C:
+			for steps := clock.due(); steps > 0; steps-- {
+				if !g.handleInputs() {
 					running = false
+					break
 				}
-			default:
-				renderer.Render()
-				time.Sleep(100 * time.Millisecond)
+				g.Update()
 			}
-		} else {
-			// Normal game loop
-			select {
-			case <-g.eventHandler.QuitChannel():
-				running = false
-			case event := <-g.eventHandler.InputEvents:
-				g.processInput(event)
-			default:
-				renderer.Render()
-				time.Sleep(100 * time.Millisecond)
+
+			if g.Broadcast != nil {
+				g.Broadcast.Publish(g)
+			}
+
+			renderer.RenderGame(g)
+
+			// Nobody can play a board they cannot see
+			if renderer.TooSmall() && !g.Paused && !g.IsGameOver {
+				g.Pause()
+			}
+
+			if frames.present(renderer) {
+				g.console.markFrame()
 			}
 		}
 	}
+
+	// Leave an autosave behind so the next launch can offer to resume
+	if !g.IsGameOver && g.pendingResume == nil {
+		g.autosave()
+	}
+	return nil
 }
 
+// handleInputs handles every key press and API command that arrived since
+// the last step. It returns false once the player quits, then the step is not
+// run, so a script ends on the same step however the steps fall into frames.
+func (g *Game) handleInputs() bool {
+	if input, ok := g.Input.(steppedInput); ok {
+		input.Step(stepInterval)
+	}
+
+	for {
+		select {
+		case event := <-g.Input.Events():
+			if !g.handleInput(event) {
+				return false
+			}
+		case cmd := <-g.apiCommands():
+			g.handleAPICommand(cmd)
+		default:
+			return !g.quitting()
+		}
+	}
+}
+
+// quitting reports whether the input has ended without waiting for it
+func (g *Game) quitting() bool {
+	select {
+	case <-g.Input.QuitChannel():
+		return true
+	default:
+		return false
+	}
+}
+
+func (g *Game) handleInput(event Event) bool {
+	// Any key cuts running animations short, the bot's moves do not
+	if !event.Bot {
+		g.UI.Animations.Skip()
+	}
+
+	switch {
+	case g.nameEntry.Active:
+		g.handleNameEntry(event)
+	case g.pendingResume != nil:
+		g.handleResumePrompt(event)
+	case g.console.Active || event.Action == "console":
+		g.handleConsoleInput(event)
+	case g.IsGameOver:
+		if event.Action == "restart" && g.Versus == nil {
+			g.Reset()
+		} else if event.Action == "quit" {
+			return false
+		}
+	default:
+		g.processInput(event)
+	}
+	return true
+}
+
+// Update runs one simulation step
 func (g *Game) Update() {
-	rendererInstance.RenderGame(g)
-	g.timer.Update()
+	if g.Versus != nil {
+		g.Versus.Update(g)
+	}
+	g.step()
+}
+
+// step advances the rules by one fixed step of game time
+func (g *Game) step() {
+	if g.IsGameOver || g.pendingResume != nil || g.Paused || g.console.Active {
+		return
+	}
+
+	g.timer.Step(stepInterval)
 
 	currentTime := g.timer.elapsed
 
 	g.drop(currentTime)
+	g.autosaveIfDue()
+}
+
+func (g *Game) Pause() {
+	if g.IsGameOver {
+		return
+	}
+
+	g.Paused = true
+	g.timer.Pause()
+	GetLoggerInstance().Info("Game paused")
+}
+
+func (g *Game) Resume() {
+	if !g.Paused {
+		return
+	}
+
+	g.Paused = false
+	g.timer.Resume()
+	GetLoggerInstance().Info("Game resumed")
+}
+
+func (g *Game) TogglePause() {
+	// The other player does not wait
+	if g.Versus != nil {
+		GetLoggerInstance().Warn("Cannot pause a versus match")
+		return
+	}
+
+	if g.Paused {
+		g.Resume()
+	} else {
+		g.Pause()
+	}
+}
+
+// Advance moves a headless game forward by the given number of milliseconds
+func (g *Game) Advance(ms int64) {
+	if g.IsGameOver {
+		return
+	}
+
+	g.timer.Advance(ms)
+	g.drop(g.timer.elapsed)
 }
 
 func (g *Game) drop(currentTime int64) {
@@ -117,6 +295,7 @@ func (g *Game) drop(currentTime int64) {
 
 			if canMoveDown {
 				g.Player.CurrentPolymino.Move(0, 1)
+				g.publishMove("gravity")
 			} else {
 				g.placeCurrentPolyomino()
 			}
@@ -126,10 +305,52 @@ func (g *Game) drop(currentTime int64) {
 	} else {
 		g.Player.CurrentPolymino = g.Player.NextPolyomino
 
-		g.Player.NextPolyomino = GeneratePolyomino()
+		g.Player.NextPolyomino = g.generatePiece()
 
 		g.lastDropTime = currentTime
+
+		g.publish(PieceSpawned{
+			At:       g.timer.elapsed,
+			Size:     len(g.Player.CurrentPolymino.Blocks),
+			Color:    pieceColor(g.Player.CurrentPolymino),
+			Position: g.Player.CurrentPolymino.Position,
+		})
+	}
+}
+
+// generatePiece returns the piece after the next one
+func (g *Game) generatePiece() *Polyomino {
+	if g.Puzzle != nil {
+		return g.Puzzle.take()
 	}
+	if g.PieceSet != nil {
+		return g.PieceSet.Generate(g.rng)
+	}
+	minSize, maxSize := g.PieceSizes.Range(g.Scoring.Level)
+	return GeneratePolyomino(g.rng, minSize, maxSize)
+}
+
+// SetPieceSet makes the game draw its pieces from set, or random polyominoes
+// when nil. The game starts over with the same seed so the first piece comes
+// from the set too.
+func (g *Game) SetPieceSet(set *PieceSet) {
+	g.PieceSet = set
+	g.ResetWithSeed(g.Seed)
+}
+
+func (g *Game) publishMove(action string) {
+	g.publish(PieceMoved{
+		At:       g.timer.elapsed,
+		Action:   action,
+		Position: g.Player.CurrentPolymino.Position,
+	})
+}
+
+func pieceColor(polyomino *Polyomino) string {
+	if len(polyomino.Blocks) == 0 {
+		return ""
+	}
+	return polyomino.Blocks[0].Color
 }
 
 func (g *Game) placeCurrentPolyomino() {
@@ -137,15 +358,20 @@ func (g *Game) placeCurrentPolyomino() {
 		return
 	}
 
+	toppedOut := false
 	for _, block := range g.Player.CurrentPolymino.Blocks {
 		absY := g.Player.CurrentPolymino.Position.Y + block.Position.Y
 		if absY < 0 {
-			GetLoggerInstance().Log("GAME OVER!")
-			g.IsGameOver = true
+			toppedOut = true
 		}
 	}
 
 	g.Player.HasSwapped = false
+	g.Stats.RecordPiece(g.Player.CurrentPolymino)
+
+	// Pieces are numbered in the order they lock
+	pieceID := g.Stats.PiecesPlaced
+	shapeID := ShapeID(g.Player.CurrentPolymino.Blocks)
 
 	for _, block := range g.Player.CurrentPolymino.Blocks {
 		absY := g.Player.CurrentPolymino.Position.Y + block.Position.Y
@@ -156,21 +382,53 @@ func (g *Game) placeCurrentPolyomino() {
 					X: g.Player.CurrentPolymino.Position.X + block.Position.X,
 					Y: absY,
 				},
-				Color: block.Color,
+				Color:   block.Color,
+				PieceID: pieceID,
+				ShapeID: shapeID,
 			}
 
 			g.placedBlocks = append(g.placedBlocks, placedBlock)
 		}
 	}
 
-	g.CheckLineClear()
+	g.Stats.RecordStackHeight(g.placedBlocks)
+
+	g.publish(PieceLocked{
+		At:          g.timer.elapsed,
+		PieceID:     pieceID,
+		Shape:       shapeID,
+		Size:        len(g.Player.CurrentPolymino.Blocks),
+		Color:       pieceColor(g.Player.CurrentPolymino),
+		Cells:       absoluteCells(g.Player.CurrentPolymino),
+		StackHeight: StackHeight(g.placedBlocks),
+	})
+
+	if toppedOut {
+		g.endGame()
+	}
+
+	clearedLines := g.CheckLineClear()
 
 	g.Player.CurrentPolymino = nil
+
+	if !g.IsGameOver {
+		g.exchangeGarbage(clearedLines)
+	}
+
+	if g.Puzzle != nil {
+		g.Puzzle.check(g)
+	}
+
+	if g.Versus != nil && !g.IsGameOver {
+		g.Versus.sendState(g)
+	}
 }
 
-func (g *Game) TryRotate() {
+// TryRotate rotates the current piece clockwise, kicking it off walls if
+// needed, and reports whether the rotation happened
+func (g *Game) TryRotate() bool {
 	if g.Player.CurrentPolymino == nil {
-		return
+		return false
 	}
 
 	originalBlocks := make([]Block, len(g.Player.CurrentPolymino.Blocks))
@@ -186,7 +444,7 @@ func (g *Game) TryRotate() {
 	g.Player.CurrentPolymino.Rotate(true)
 
 	if !g.checkCollision() {
-		return
+		return true
 	}
 
 	_, originalCollisionType := g.checkCollisionWithType()
@@ -198,7 +456,7 @@ func (g *Game) TryRotate() {
 			g.Player.CurrentPolymino.Blocks[i].Position.X = originalBlocks[i].Position.X
 			g.Player.CurrentPolymino.Blocks[i].Position.Y = originalBlocks[i].Position.Y
 		}
-		return
+		return false
 	}
 
 	kickOffsets := []struct{ x, y int }{
@@ -216,7 +474,7 @@ func (g *Game) TryRotate() {
 		collision, _ := g.checkCollisionWithType()
 
 		if !collision {
-			return
+			return true
 		}
 	}
 
@@ -227,6 +485,7 @@ func (g *Game) TryRotate() {
 		g.Player.CurrentPolymino.Blocks[i].Position.X = originalBlocks[i].Position.X
 		g.Player.CurrentPolymino.Blocks[i].Position.Y = originalBlocks[i].Position.Y
 	}
+	return false
 }
 
 func (g *Game) checkCollisionWithType() (bool, string) {
@@ -281,32 +540,110 @@ func (g *Game) checkMovementCollision(dx, dy int) bool {
 }
 
 func (g *Game) processInput(event Event) {
-	logger.Log("Processing input event: " + event.Action)
+	logger.Debug("Processing input event", "action", event.Action)
 
-	if g.Player.CurrentPolymino == nil {
+	switch event.Action {
+	case "stats":
+		if g.UI != nil {
+			g.UI.ShowStats = !g.UI.ShowStats
+		}
+		return
+	case "logs":
+		GetLoggerInstance().ToggleVisible()
+		return
+	case "logLevel":
+		GetLoggerInstance().CycleLevel()
+		return
+	case "save":
+		g.SaveGame()
+		return
+	case "load":
+		g.LoadGame()
+		return
+	case "pause":
+		g.TogglePause()
 		return
 	}
 
+	if g.Player.CurrentPolymino == nil || g.Paused {
+		return
+	}
+
+	// Only key presses that move, turn, swap or drop the piece count as inputs
+	handled := false
 	switch event.Action {
-	case "up":
-		g.TryRotate()
+	case "up", "space":
+		if g.TryRotate() {
+			g.publishMove(event.Action)
+			handled = true
+		}
 	case "down":
 		if !g.checkMovementCollision(0, 1) {
 			g.Player.CurrentPolymino.Move(0, 1)
+			g.publishMove(event.Action)
+			handled = true
 		}
 	case "left":
 		if !g.checkMovementCollision(-1, 0) {
 			g.Player.CurrentPolymino.Move(-1, 0)
+			g.publishMove(event.Action)
+			handled = true
 		}
 	case "right":
 		if !g.checkMovementCollision(1, 0) {
 			g.Player.CurrentPolymino.Move(1, 0)
+			g.publishMove(event.Action)
+			handled = true
 		}
-	case "space":
-		g.TryRotate()
 	case "swap":
-		g.SwapBlocks()
+		handled = g.SwapBlocks()
 	case "hardDrop":
 		g.HardDrop()
+		handled = true
+	case "moveTo":
+		handled = g.moveToClick(event.X, event.Y)
+	}
+
+	if handled {
+		g.Stats.RecordInput()
+	}
+}
+
+// moveToClick slides the current piece sideways until its middle is over the
+// clicked column of the field, or a wall or block stops it. It reports
+// whether the piece moved.
+func (g *Game) moveToClick(screenX, screenY int) bool {
+	renderer := GetRendererInstance()
+	if !renderer.IsInGameArea(screenX, screenY) {
+		return false
+	}
+	column, _ := renderer.ScreenToGameCoordinates(screenX, screenY)
+
+	piece := g.Player.CurrentPolymino
+	left, right := GameFieldWidth, -1
+	for _, block := range piece.Blocks {
+		left = min(left, piece.Position.X+block.Position.X)
+		right = max(right, piece.Position.X+block.Position.X)
+	}
+
+	moved := false
+	for dx := column - (left+right)/2; dx != 0; {
+		step := 1
+		if dx < 0 {
+			step = -1
+		}
+		if g.checkMovementCollision(step, 0) {
+			break
+		}
+
+		piece.Move(step, 0)
+		moved = true
+		dx -= step
+		if step < 0 {
+			g.publishMove("left")
+		} else {
+			g.publishMove("right")
+		}
 	}
+	return moved
 }
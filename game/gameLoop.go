@@ -0,0 +1,62 @@
+package game
+
+import "time"
+
+const (
+	// StepRate is how many times per second the rules advance
+	StepRate = 60
+	// MaxFrameRate caps how often the terminal is redrawn
+	MaxFrameRate = 30
+
+	stepInterval  = time.Second / StepRate
+	frameInterval = time.Second / MaxFrameRate
+
+	// maxCatchUpSteps limits the steps run at once after a stall, the game
+	// slows down for a moment instead of jumping ahead
+	maxCatchUpSteps = 5
+
+	// botActionInterval paces the bot in game time, one action per interval
+	botActionInterval = 100
+)
+
+// stepClock turns the real time passed into a number of fixed steps
+type stepClock struct {
+	last   time.Time
+	behind time.Duration
+}
+
+func newStepClock() *stepClock {
+	return &stepClock{last: time.Now()}
+}
+
+// due returns how many steps to run for the time since the last call
+func (c *stepClock) due() int {
+	now := time.Now()
+	c.behind += now.Sub(c.last)
+	c.last = now
+
+	steps := int(c.behind / stepInterval)
+	if steps > maxCatchUpSteps {
+		c.behind = 0
+		return maxCatchUpSteps
+	}
+	c.behind -= time.Duration(steps) * stepInterval
+	return steps
+}
+
+// frameLimiter redraws the terminal at most MaxFrameRate times a second, and
+// only when the frame differs from the one already on screen
+type frameLimiter struct {
+	last time.Time
+}
+
+// present prints the renderer's frame if it is due, it reports whether it did
+func (f *frameLimiter) present(r *Renderer) bool {
+	if time.Since(f.last) < frameInterval || !r.Changed() {
+		return false
+	}
+
+	r.Render()
+	f.last = time.Now()
+	return true
+}
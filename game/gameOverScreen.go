@@ -1,14 +1,28 @@
 package game
 
-func (ui *Interface) DrawGameOverScreen() {
-	gameOverX := GameFieldStartX + (GameFieldWidth * BlockWidth / 4)
-	gameOverY := GameFieldStartY + (GameFieldHeight / 2)
+import "fmt"
+
+func (ui *Interface) DrawGameOverScreen(entry NameEntry) {
+	gameOverX := ui.view.FieldX + (GameFieldWidth * BlockWidth / 4)
+	gameOverY := ui.view.FieldY + (GameFieldHeight / 2)
 
 	gameOverText := "GAME OVER"
 	for i, char := range gameOverText {
 		ui.renderer.Pixels[gameOverY][gameOverX+i] = ColoredPixel{Char: char, Color: "red"}
 	}
 
+	if entry.Active {
+		ui.drawNameEntry(entry, gameOverX-2, gameOverY+2)
+		return
+	}
+
+	if entry.Rank > 0 {
+		rankText := fmt.Sprintf("NEW HIGH SCORE #%d", entry.Rank)
+		for i, char := range rankText {
+			ui.renderer.Pixels[gameOverY+1][gameOverX-4+i] = ColoredPixel{Char: char, Color: "yellow"}
+		}
+	}
+
 	instructionsText := "Press R to restart"
 	instructionsX := gameOverX - 2
 	instructionsY := gameOverY + 2
@@ -23,3 +37,101 @@ func (ui *Interface) DrawGameOverScreen() {
 		ui.renderer.Pixels[quitY][quitX+i] = ColoredPixel{Char: char, Color: "white"}
 	}
 }
+
+func (ui *Interface) drawNameEntry(entry NameEntry, x, y int) {
+	promptText := "NEW HIGH SCORE!"
+	for i, char := range promptText {
+		ui.renderer.Pixels[y][x+i] = ColoredPixel{Char: char, Color: "yellow"}
+	}
+
+	nameText := "Name: " + string(entry.Name) + "_"
+	for i, char := range nameText {
+		ui.renderer.Pixels[y+1][x+i] = ColoredPixel{Char: char, Color: "white"}
+	}
+
+	hintText := "ENTER to save"
+	for i, char := range hintText {
+		ui.renderer.Pixels[y+2][x+i] = ColoredPixel{Char: char, Color: "white"}
+	}
+}
+
+// DrawStatsSummary shows the full statistics in the upper half of the field
+func (ui *Interface) DrawStatsSummary(stats *Statistics, elapsed int64) {
+	summaryX := ui.view.FieldX + 2
+	summaryY := ui.view.FieldY + 1
+	summaryWidth := GameFieldWidth*BlockWidth - 4
+
+	lines := append([]string{"SUMMARY"}, stats.Lines(elapsed)...)
+	for row, line := range lines {
+		for x := 0; x < summaryWidth; x++ {
+			ui.renderer.Pixels[summaryY+row][summaryX+x] = ColoredPixel{Char: ' ', Color: ""}
+		}
+
+		color := "cyan"
+		if row == 0 {
+			color = "white"
+		}
+		for i, char := range line {
+			if i < summaryWidth {
+				ui.renderer.Pixels[summaryY+row][summaryX+i] = ColoredPixel{Char: char, Color: color}
+			}
+		}
+	}
+}
+
+func (ui *Interface) DrawResumePrompt(state *SaveState) {
+	promptX := ui.view.FieldX + 4
+	promptY := ui.view.FieldY + (GameFieldHeight / 2)
+
+	lines := []string{
+		"RESUME SAVED GAME?",
+		fmt.Sprintf("Score %d  Level %d", state.Scoring.Score, state.Scoring.Level),
+		"Y - resume  N - new",
+	}
+
+	for row, line := range lines {
+		color := "white"
+		if row == 0 {
+			color = "yellow"
+		}
+		for i, char := range line {
+			ui.renderer.Pixels[promptY+row][promptX+i] = ColoredPixel{Char: char, Color: color}
+		}
+	}
+}
+
+func (ui *Interface) DrawVersusResult(session *VersusSession) {
+	resultX := ui.view.FieldX + (GameFieldWidth * BlockWidth / 4)
+	resultY := ui.view.FieldY + (GameFieldHeight / 2)
+
+	lines := []string{"YOU LOSE", "", "ESC to quit"}
+	color := "red"
+	if session.Result == VersusWin {
+		lines[0] = "YOU WIN!"
+		color = "green"
+		if session.Opponent.Left {
+			lines[1] = "Opponent left"
+		}
+	}
+
+	for row, line := range lines {
+		if row > 0 {
+			color = "white"
+		}
+		for i, char := range line {
+			ui.renderer.Pixels[resultY+row][resultX+i] = ColoredPixel{Char: char, Color: color}
+		}
+	}
+}
+
+func (ui *Interface) DrawPausedScreen() {
+	pausedX := ui.view.FieldX + (GameFieldWidth * BlockWidth / 4)
+	pausedY := ui.view.FieldY + (GameFieldHeight / 2)
+
+	for i, char := range "PAUSED" {
+		ui.renderer.Pixels[pausedY][pausedX+2+i] = ColoredPixel{Char: char, Color: "yellow"}
+	}
+	for i, char := range "P to resume" {
+		ui.renderer.Pixels[pausedY+2][pausedX+i] = ColoredPixel{Char: char, Color: "white"}
+	}
+}
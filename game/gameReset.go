@@ -1,15 +1,34 @@
 package game
 
 func (g *Game) Reset() {
+	g.ResetWithSeed(NewSeed())
+}
+
+// ResetWithSeed starts over with a known seed, so several boards can share
+// the same piece sequence.
+func (g *Game) ResetWithSeed(seed int64) {
 	g.placedBlocks = []Block{}
 	g.lastDropTime = 0
+	g.lastAutosave = 0
 	g.IsGameOver = false
+	g.Paused = false
+	g.nameEntry = NameEntry{}
+	g.Garbage.Clear()
 
-	g.Player = NewPlayer()
+	g.Seed = seed
+	g.rng = NewRandomizer(g.Seed)
 
+	// The piece size range can depend on the level, so score first
 	g.Scoring = NewScoringSystem()
+	g.Stats = NewStatistics()
+
+	g.Player = NewPlayer(g.generatePiece())
 
 	g.timer.Reset()
 
-	GetLoggerInstance().Log("Game reset")
+	if g.Puzzle != nil {
+		g.Puzzle.start(g)
+	}
+
+	GetLoggerInstance().Info("Game reset", "seed", seed)
 }
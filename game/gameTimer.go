@@ -2,56 +2,52 @@ package game
 
 import "time"
 
+// GameTimer is the game clock. It only moves when the game loop steps it, so
+// game time is a count of fixed steps and never depends on how fast frames
+// are drawn.
 type GameTimer struct {
-	elapsed    int64
-	startTime  time.Time
-	lastTick   time.Time
-	tickRate   time.Duration
-	isPaused   bool
-	pausedTime int64
+	elapsed  int64
+	carry    time.Duration // Part of a millisecond left over from the last steps
+	isPaused bool
 }
 
 func NewGameTimer() *GameTimer {
-	return &GameTimer{
-		elapsed:    0,
-		startTime:  time.Now(),
-		lastTick:   time.Now(),
-		tickRate:   100 * time.Millisecond,
-		isPaused:   false,
-		pausedTime: 0,
-	}
+	return &GameTimer{}
 }
 
-func (t *GameTimer) Update() {
+// Step advances the clock by one simulation step
+func (t *GameTimer) Step(step time.Duration) {
 	if t.isPaused {
 		return
 	}
 
-	now := time.Now()
-	elapsed := now.Sub(t.lastTick)
-	t.elapsed += elapsed.Milliseconds()
-	t.lastTick = now
+	t.carry += step
+	ms := t.carry.Milliseconds()
+	t.elapsed += ms
+	t.carry -= time.Duration(ms) * time.Millisecond
 }
 
 func (t *GameTimer) Reset() {
-	now := time.Now()
 	t.elapsed = 0
-	t.startTime = now
-	t.lastTick = now
-	t.pausedTime = 0
+	t.carry = 0
+	t.isPaused = false
 }
 
 func (t *GameTimer) Pause() {
-	if !t.isPaused {
-		t.isPaused = true
-		t.pausedTime = time.Now().UnixMilli()
-	}
+	t.isPaused = true
 }
 
 func (t *GameTimer) Resume() {
-	if t.isPaused {
-		t.isPaused = false
-		now := time.Now()
-		t.lastTick = now
-	}
+	t.isPaused = false
+}
+
+// Restore continues counting from a previously recorded elapsed time
+func (t *GameTimer) Restore(elapsed int64) {
+	t.elapsed = elapsed
+	t.carry = 0
+}
+
+// Advance adds simulated time, used by headless games instead of the clock
+func (t *GameTimer) Advance(ms int64) {
+	t.elapsed += ms
 }
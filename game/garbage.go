@@ -0,0 +1,113 @@
+package game
+
+const GarbageColor = "white"
+
+// GarbageTarget receives the garbage rows a game sends when it clears lines
+type GarbageTarget interface {
+	SendGarbage(lines int)
+}
+
+// GarbageQueue holds the hole column of every incoming garbage row until it
+// is added to the board after the next lock.
+type GarbageQueue struct {
+	holes []int
+}
+
+func (q *GarbageQueue) Push(lines, hole int) {
+	for i := 0; i < lines; i++ {
+		q.holes = append(q.holes, hole)
+	}
+}
+
+func (q *GarbageQueue) Pending() int {
+	return len(q.holes)
+}
+
+// Cancel removes up to lines queued rows and returns the part of the attack
+// that was not used up.
+func (q *GarbageQueue) Cancel(lines int) int {
+	cancelled := min(lines, len(q.holes))
+	q.holes = q.holes[cancelled:]
+	return lines - cancelled
+}
+
+func (q *GarbageQueue) Clear() {
+	q.holes = nil
+}
+
+// exchangeGarbage runs after every lock: the clear first cancels rows waiting
+// for us, the rest is sent, then whatever is still queued rises.
+func (g *Game) exchangeGarbage(clearedLines int) {
+	if g.GarbageTarget == nil {
+		return
+	}
+
+	if attack := g.Garbage.Cancel(GarbageForLines(clearedLines)); attack > 0 {
+		g.GarbageTarget.SendGarbage(attack)
+	}
+
+	// Rows sent together share a hole, so add them in runs
+	for len(g.Garbage.holes) > 0 && !g.IsGameOver {
+		holes := g.Garbage.holes
+		run := 1
+		for run < len(holes) && holes[run] == holes[0] {
+			run++
+		}
+		g.Garbage.holes = holes[run:]
+		g.AddGarbageRows(run, holes[0])
+	}
+}
+
+// GarbageForLines is how many garbage rows a clear sends to the opponent. It
+// follows the usual 0/1/2/4 table, extended for the taller polyominoes.
+func GarbageForLines(lines int) int {
+	switch {
+	case lines <= 1:
+		return 0
+	case lines == 4:
+		return 4
+	case lines < 4:
+		return lines - 1
+	default:
+		return lines
+	}
+}
+
+// AddGarbageRows pushes the stack up and fills the bottom rows with garbage,
+// leaving one open cell in column hole. Blocks pushed above the field top out.
+func (g *Game) AddGarbageRows(lines, hole int) {
+	if lines <= 0 {
+		return
+	}
+
+	toppedOut := false
+	for i := range g.placedBlocks {
+		g.placedBlocks[i].Position.Y -= lines
+		if g.placedBlocks[i].Position.Y < 0 {
+			toppedOut = true
+		}
+	}
+
+	// Keep an active piece clear of the rising stack
+	if g.Player.CurrentPolymino != nil {
+		g.Player.CurrentPolymino.Position.Y -= lines
+	}
+
+	for y := GameFieldHeight - lines; y < GameFieldHeight; y++ {
+		for x := 0; x < GameFieldWidth; x++ {
+			if x == hole {
+				continue
+			}
+			g.placedBlocks = append(g.placedBlocks, Block{
+				Position: Position{X: x, Y: y},
+				Color:    GarbageColor,
+			})
+		}
+	}
+
+	GetLoggerInstance().Info("Received garbage rows", "lines", lines)
+
+	if toppedOut {
+		g.endGame()
+	}
+}
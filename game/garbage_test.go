@@ -0,0 +1,122 @@
+package game
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGarbageForLines(t *testing.T) {
+	tests := []struct {
+		lines int
+		want  int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 1},
+		{3, 2},
+		{4, 4},
+		{5, 5},
+		{MaxClearLines, MaxClearLines},
+	}
+
+	for _, tt := range tests {
+		if got := GarbageForLines(tt.lines); got != tt.want {
+			t.Errorf("GarbageForLines(%d) = %d, want %d", tt.lines, got, tt.want)
+		}
+	}
+}
+
+func TestGarbageQueueCancel(t *testing.T) {
+	tests := []struct {
+		name        string
+		queued      int
+		attack      int
+		wantLeft    int
+		wantPending int
+	}{
+		{"nothing queued", 0, 3, 3, 0},
+		{"attack used up", 4, 2, 0, 2},
+		{"exactly cancelled", 3, 3, 0, 0},
+		{"attack left over", 2, 5, 3, 0},
+		{"no attack", 2, 0, 0, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var q GarbageQueue
+			q.Push(tt.queued, 4)
+
+			if left := q.Cancel(tt.attack); left != tt.wantLeft {
+				t.Errorf("Cancel(%d) = %d, want %d", tt.attack, left, tt.wantLeft)
+			}
+			if q.Pending() != tt.wantPending {
+				t.Errorf("%d rows pending, want %d", q.Pending(), tt.wantPending)
+			}
+		})
+	}
+}
+
+// recordedGarbage keeps what a game sends instead of passing it on
+type recordedGarbage struct {
+	sent []int
+}
+
+func (r *recordedGarbage) SendGarbage(lines int) {
+	r.sent = append(r.sent, lines)
+}
+
+func TestExchangeGarbage(t *testing.T) {
+	tests := []struct {
+		name      string
+		queued    [][2]int // Lines and hole of every push
+		cleared   int
+		wantSent  []int
+		wantRisen int
+	}{
+		{name: "quiet lock"},
+		{name: "single sends nothing", cleared: 1},
+		{name: "double sends one", cleared: 2, wantSent: []int{1}},
+		{name: "four sends four", cleared: 4, wantSent: []int{4}},
+		{name: "queued rows rise", queued: [][2]int{{2, 3}}, wantRisen: 2},
+		{name: "clear cancels before rising", queued: [][2]int{{3, 3}}, cleared: 3, wantRisen: 1},
+		{name: "clear cancels and sends the rest", queued: [][2]int{{1, 3}}, cleared: 4, wantSent: []int{3}},
+		{name: "pushes rise with their own holes", queued: [][2]int{{1, 3}, {2, 8}}, wantRisen: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewHeadlessGame(1)
+			target := &recordedGarbage{}
+			g.GarbageTarget = target
+			for _, push := range tt.queued {
+				g.Garbage.Push(push[0], push[1])
+			}
+
+			g.exchangeGarbage(tt.cleared)
+
+			if !slices.Equal(target.sent, tt.wantSent) {
+				t.Errorf("sent %v, want %v", target.sent, tt.wantSent)
+			}
+			if g.Garbage.Pending() != 0 {
+				t.Errorf("%d rows still queued after the lock", g.Garbage.Pending())
+			}
+			if got := StackHeight(g.placedBlocks); got != tt.wantRisen {
+				t.Errorf("stack is %d rows high, want %d", got, tt.wantRisen)
+			}
+		})
+	}
+}
+
+func TestAddGarbageRowsLeavesTheHole(t *testing.T) {
+	g := NewHeadlessGame(1)
+	g.AddGarbageRows(2, 6)
+
+	board := NewBoard(g.placedBlocks)
+	for y := GameFieldHeight - 2; y < GameFieldHeight; y++ {
+		for x := 0; x < GameFieldWidth; x++ {
+			if board.Cells[y][x] == (x == 6) {
+				t.Errorf("cell %d,%d filled = %v", x, y, board.Cells[y][x])
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// GlyphSet decides which characters the screen is drawn with. Everything is
+// drawn with the double-line box characters and Render swaps them for the
+// set's Box characters on output; blocks are drawn as two cells each.
+type GlyphSet struct {
+	Name  string
+	Block [2]rune
+	Ghost [2]rune
+	Trail [2]rune       // Streak left behind by a hard drop
+	Small [3]rune       // Shrunk blocks in the NEXT box: top half, bottom half, both
+	Box   map[rune]rune // Double-line character to its replacement, nil keeps them
+}
+
+var GlyphSets = map[string]GlyphSet{
+	"unicode": {
+		Name:  "unicode",
+		Block: [2]rune{'█', '█'},
+		Ghost: [2]rune{'░', '░'},
+		Trail: [2]rune{'│', '│'},
+		Small: [3]rune{'▀', '▄', '█'},
+	},
+	"single": {
+		Name:  "single",
+		Block: [2]rune{'█', '█'},
+		Ghost: [2]rune{'░', '░'},
+		Trail: [2]rune{'│', '│'},
+		Small: [3]rune{'▀', '▄', '█'},
+		Box: map[rune]rune{
+			'═': '─', '║': '│',
+			'╔': '┌', '╗': '┐', '╚': '└', '╝': '┘',
+			'╦': '┬', '╩': '┴', '╠': '├', '╣': '┤',
+		},
+	},
+	"ascii": {
+		Name:  "ascii",
+		Block: [2]rune{'[', ']'},
+		Ghost: [2]rune{'.', '.'},
+		Trail: [2]rune{':', ':'},
+		Small: [3]rune{'\'', '.', '#'},
+		Box: map[rune]rune{
+			'═': '-', '║': '|',
+			'╔': '+', '╗': '+', '╚': '+', '╝': '+',
+			'╦': '+', '╩': '+', '╠': '+', '╣': '+',
+		},
+	},
+}
+
+// GlyphSetByName looks up a glyph set, "" or "auto" detects one
+func GlyphSetByName(name string) (GlyphSet, error) {
+	if name == "" || name == "auto" {
+		return DetectGlyphSet(), nil
+	}
+
+	glyphs, ok := GlyphSets[name]
+	if !ok {
+		return GlyphSet{}, fmt.Errorf("unknown glyph set %q (unicode, single or ascii)", name)
+	}
+	return glyphs, nil
+}
+
+// DetectGlyphSet falls back to ASCII when the locale is not UTF-8 or the
+// terminal is known to lack box drawing characters
+func DetectGlyphSet() GlyphSet {
+	switch os.Getenv("TERM") {
+	case "dumb", "vt52", "vt100", "vt220":
+		return GlyphSets["ascii"]
+	}
+
+	// The first one set wins, like setlocale does
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		locale := os.Getenv(name)
+		if locale == "" {
+			continue
+		}
+
+		locale = strings.ToLower(locale)
+		if strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8") {
+			return GlyphSets["unicode"]
+		}
+		return GlyphSets["ascii"]
+	}
+
+	return GlyphSets["unicode"]
+}
+
+// glyph returns the character to print for a drawn one
+func (s GlyphSet) glyph(char rune) rune {
+	if replacement, ok := s.Box[char]; ok {
+		return replacement
+	}
+	return char
+}
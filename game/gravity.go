@@ -0,0 +1,144 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gravity modes decide what happens to the blocks above a cleared row
+const (
+	// GravityNaive shifts everything above a cleared row down by one row,
+	// blocks can be left floating over holes
+	GravityNaive = "naive"
+	// GravitySticky lets every group of touching blocks fall as one
+	GravitySticky = "sticky"
+	// GravityCascade lets what is left of every piece fall on its own
+	GravityCascade = "cascade"
+)
+
+var GravityModes = []string{GravityNaive, GravitySticky, GravityCascade}
+
+func ParseGravity(name string) (string, error) {
+	for _, mode := range GravityModes {
+		if name == mode {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("unknown gravity %q (%s)", name, strings.Join(GravityModes, ", "))
+}
+
+// removeRows takes the full rows out of the stack and lets the rest fall as
+// the gravity mode says. rows must be sorted top to bottom.
+func (g *Game) removeRows(rows []int) {
+	full := make(map[int]bool, len(rows))
+	for _, y := range rows {
+		full[y] = true
+	}
+
+	remaining := []Block{}
+	for _, block := range g.placedBlocks {
+		if !full[block.Position.Y] {
+			remaining = append(remaining, block)
+		}
+	}
+	g.placedBlocks = remaining
+
+	switch g.Gravity {
+	case GravitySticky:
+		g.settle(func(a, b Block) bool { return true })
+	case GravityCascade:
+		g.settle(func(a, b Block) bool { return a.PieceID == b.PieceID })
+	default:
+		for _, fullY := range rows {
+			for i := range g.placedBlocks {
+				if g.placedBlocks[i].Position.Y < fullY {
+					g.placedBlocks[i].Position.Y++
+				}
+			}
+		}
+	}
+}
+
+// settle lets groups of blocks fall until each rests on the floor or on
+// another group. Touching blocks for which joined is true form one group.
+func (g *Game) settle(joined func(a, b Block) bool) {
+	groups := blockGroups(g.placedBlocks, joined)
+
+	groupAt := make(map[Position]int, len(g.placedBlocks))
+	for group, members := range groups {
+		for _, i := range members {
+			groupAt[g.placedBlocks[i].Position] = group
+		}
+	}
+
+	canFall := func(group int) bool {
+		for _, i := range groups[group] {
+			below := g.placedBlocks[i].Position
+			below.Y++
+			if below.Y >= GameFieldHeight {
+				return false
+			}
+			if other, ok := groupAt[below]; ok && other != group {
+				return false
+			}
+		}
+		return true
+	}
+
+	rows := 0
+	for moved := true; moved; {
+		moved = false
+		for group, members := range groups {
+			if !canFall(group) {
+				continue
+			}
+
+			for _, i := range members {
+				delete(groupAt, g.placedBlocks[i].Position)
+			}
+			for _, i := range members {
+				g.placedBlocks[i].Position.Y++
+				groupAt[g.placedBlocks[i].Position] = group
+			}
+			moved = true
+		}
+		if moved {
+			rows++
+		}
+	}
+
+	GetLoggerInstance().Debug("Settled blocks", "gravity", g.Gravity, "groups", len(groups), "passes", rows)
+}
+
+// blockGroups splits blocks into groups of orthogonally touching blocks,
+// returned as indices into blocks
+func blockGroups(blocks []Block, joined func(a, b Block) bool) [][]int {
+	index := make(map[Position]int, len(blocks))
+	for i, block := range blocks {
+		index[block.Position] = i
+	}
+
+	seen := make([]bool, len(blocks))
+	groups := [][]int{}
+	for start := range blocks {
+		if seen[start] {
+			continue
+		}
+
+		seen[start] = true
+		group := []int{start}
+		for next := 0; next < len(group); next++ {
+			block := blocks[group[next]]
+			for _, d := range []Position{{X: 1}, {X: -1}, {Y: 1}, {Y: -1}} {
+				neighbour, ok := index[Position{X: block.X + d.X, Y: block.Y + d.Y}]
+				if !ok || seen[neighbour] || !joined(block, blocks[neighbour]) {
+					continue
+				}
+				seen[neighbour] = true
+				group = append(group, neighbour)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
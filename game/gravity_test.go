@@ -0,0 +1,207 @@
+package game
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// pieceBlocks turns rows into blocks like boardBlocks, a digit is a block of
+// that piece and '#' a garbage block
+func pieceBlocks(rows ...string) []Block {
+	var blocks []Block
+	top := GameFieldHeight - len(rows)
+	for i, row := range rows {
+		for x, c := range row {
+			switch {
+			case c == '#':
+				blocks = append(blocks, Block{Position: Position{X: x, Y: top + i}, Color: GarbageColor})
+			case c >= '1' && c <= '9':
+				blocks = append(blocks, Block{Position: Position{X: x, Y: top + i}, Color: "red", PieceID: int(c - '0')})
+			}
+		}
+	}
+	return blocks
+}
+
+// bottomRows draws the lowest n rows of blocks the way pieceBlocks reads
+// them, without the empty cells at the end of a row
+func bottomRows(blocks []Block, n int) []string {
+	rows := make([][]rune, n)
+	for i := range rows {
+		rows[i] = []rune(strings.Repeat(".", GameFieldWidth))
+	}
+	for _, block := range blocks {
+		i := block.Y - (GameFieldHeight - n)
+		if i < 0 {
+			continue
+		}
+		rows[i][block.X] = '#'
+		if block.PieceID > 0 {
+			rows[i][block.X] = rune('0' + block.PieceID)
+		}
+	}
+
+	drawn := make([]string, n)
+	for i, row := range rows {
+		drawn[i] = strings.TrimRight(string(row), ".")
+	}
+	return drawn
+}
+
+func TestBlockGroups(t *testing.T) {
+	sameBlock := func(a, b Block) bool { return true }
+	samePiece := func(a, b Block) bool { return a.PieceID == b.PieceID }
+
+	tests := []struct {
+		name   string
+		rows   []string
+		joined func(a, b Block) bool
+		want   []int // Group sizes, smallest first
+	}{
+		{"nothing", nil, sameBlock, nil},
+		{"apart", []string{"1.1"}, sameBlock, []int{1, 1}},
+		{"corner to corner", []string{"1.", ".1"}, sameBlock, []int{1, 1}},
+		{"bent line", []string{"1..", "111"}, sameBlock, []int{4}},
+		{"around a hole", []string{"111", "1.1", "111"}, sameBlock, []int{8}},
+		{"two pieces touching", []string{"12", "12"}, sameBlock, []int{4}},
+		{"two pieces touching, by piece", []string{"12", "12"}, samePiece, []int{2, 2}},
+		{"piece cut in two", []string{"1", "#", "1"}, samePiece, []int{1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := pieceBlocks(tt.rows...)
+
+			var sizes []int
+			seen := make([]bool, len(blocks))
+			for _, group := range blockGroups(blocks, tt.joined) {
+				sizes = append(sizes, len(group))
+				for _, i := range group {
+					if seen[i] {
+						t.Fatalf("block %d is in two groups", i)
+					}
+					seen[i] = true
+				}
+			}
+			sort.Ints(sizes)
+
+			if !slices.Equal(sizes, tt.want) {
+				t.Errorf("group sizes %v, want %v", sizes, tt.want)
+			}
+		})
+	}
+}
+
+func TestLineClearGravity(t *testing.T) {
+	tests := []struct {
+		name      string
+		gravity   string
+		rows      []string
+		want      []string
+		wantLines int
+		wantChain int
+		wantScore int
+	}{
+		{
+			name:      "naive leaves blocks floating",
+			gravity:   GravityNaive,
+			rows:      []string{"4", "", "###############", "3"},
+			want:      []string{"", "", "4", "", "3"},
+			wantLines: 1,
+			wantChain: 1,
+			wantScore: 100,
+		},
+		{
+			name:      "sticky drops floating blocks",
+			gravity:   GravitySticky,
+			rows:      []string{"4", "", "###############", "3"},
+			want:      []string{"", "", "", "4", "3"},
+			wantLines: 1,
+			wantChain: 1,
+			wantScore: 100,
+		},
+		{
+			name:      "sticky keeps touching pieces together",
+			gravity:   GravitySticky,
+			rows:      []string{"12", "###############", "3"},
+			want:      []string{"", "", "12", "3"},
+			wantLines: 1,
+			wantChain: 1,
+			wantScore: 100,
+		},
+		{
+			name:      "cascade drops every piece on its own",
+			gravity:   GravityCascade,
+			rows:      []string{"12", "###############", "3"},
+			want:      []string{"", "", "1", "32"},
+			wantLines: 1,
+			wantChain: 1,
+			wantScore: 100,
+		},
+		{
+			name:      "naive does not chain",
+			gravity:   GravityNaive,
+			rows:      []string{"5", "###############", ".##############"},
+			want:      []string{"", "", "5", ".##############"},
+			wantLines: 1,
+			wantChain: 1,
+			wantScore: 100,
+		},
+		{
+			name:      "sticky chain scores double",
+			gravity:   GravitySticky,
+			rows:      []string{"5", "###############", ".##############"},
+			want:      []string{"", "", "", ""},
+			wantLines: 2,
+			wantChain: 2,
+			wantScore: 100 + 2*100,
+		},
+		{
+			name:      "cascade chain scores double",
+			gravity:   GravityCascade,
+			rows:      []string{"5", "###############", ".##############"},
+			want:      []string{"", "", "", ""},
+			wantLines: 2,
+			wantChain: 2,
+			wantScore: 100 + 2*100,
+		},
+		{
+			name:      "third link scores triple",
+			gravity:   GravityCascade,
+			rows:      []string{".6", "5", "###############", ".##############", "#.#############"},
+			want:      []string{"", "", "", "", ""},
+			wantLines: 3,
+			wantChain: 3,
+			wantScore: 100 + 2*100 + 3*100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewHeadlessGame(1)
+			g.Gravity = tt.gravity
+			g.placedBlocks = pieceBlocks(tt.rows...)
+
+			chains := 0
+			On(g.Events, func(e LinesCleared) { chains = max(chains, e.Chain) })
+
+			if lines := g.CheckLineClear(); lines != tt.wantLines {
+				t.Errorf("cleared %d lines, want %d", lines, tt.wantLines)
+			}
+			if g.Scoring.Score != tt.wantScore {
+				t.Errorf("score %d, want %d", g.Scoring.Score, tt.wantScore)
+			}
+			if chains != tt.wantChain {
+				t.Errorf("chain of %d clears, want %d", chains, tt.wantChain)
+			}
+			if len(g.placedBlocks) != len(pieceBlocks(tt.want...)) {
+				t.Errorf("%d blocks left, want %d", len(g.placedBlocks), len(pieceBlocks(tt.want...)))
+			}
+			if got := bottomRows(g.placedBlocks, len(tt.want)); !slices.Equal(got, tt.want) {
+				t.Errorf("stack\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,251 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	MaxHighScores    = 10
+	MaxNameLength    = 12
+	DefaultName      = "PLAYER"
+	highScoresFile   = "highscores.json"
+	dataDirName      = "gotris"
+	highScoresFormat = 1
+)
+
+type HighScoreEntry struct {
+	Name  string    `json:"name"`
+	Score int       `json:"score"`
+	Lines int       `json:"lines"`
+	Level int       `json:"level"`
+	Time  int64     `json:"timeMs"`
+	Seed  int64     `json:"seed"`
+	Date  time.Time `json:"date"`
+}
+
+type HighScoreTable struct {
+	Version int                         `json:"version"`
+	Modes   map[string][]HighScoreEntry `json:"modes"`
+	path    string
+}
+
+// DataDir returns the directory used for persistent game data, following the
+// XDG base directory spec ($XDG_DATA_HOME, falling back to ~/.local/share).
+func DataDir() string {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return dataDirName
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, dataDirName)
+}
+
+func HighScoresPath() string {
+	return filepath.Join(DataDir(), highScoresFile)
+}
+
+func NewHighScoreTable(path string) *HighScoreTable {
+	return &HighScoreTable{
+		Version: highScoresFormat,
+		Modes:   make(map[string][]HighScoreEntry),
+		path:    path,
+	}
+}
+
+// LoadHighScores reads the table at path. A missing or unreadable file yields
+// an empty table so a broken file never prevents the game from starting.
+func LoadHighScores(path string) *HighScoreTable {
+	table := NewHighScoreTable(path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			GetLoggerInstance().Error("Could not read high scores", "err", err)
+		}
+		return table
+	}
+
+	if err := json.Unmarshal(data, table); err != nil {
+		GetLoggerInstance().Error("Could not parse high scores", "err", err)
+		return NewHighScoreTable(path)
+	}
+	if table.Modes == nil {
+		table.Modes = make(map[string][]HighScoreEntry)
+	}
+	table.path = path
+
+	return table
+}
+
+func (t *HighScoreTable) Save() error {
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated table
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, t.path)
+}
+
+func (t *HighScoreTable) Top(mode string) []HighScoreEntry {
+	return t.Modes[mode]
+}
+
+func (t *HighScoreTable) Best(mode string) (HighScoreEntry, bool) {
+	if t == nil {
+		return HighScoreEntry{}, false
+	}
+
+	entries := t.Modes[mode]
+	if len(entries) == 0 {
+		return HighScoreEntry{}, false
+	}
+	return entries[0], true
+}
+
+func (t *HighScoreTable) Qualifies(mode string, score int) bool {
+	if score <= 0 {
+		return false
+	}
+
+	entries := t.Modes[mode]
+	if len(entries) < MaxHighScores {
+		return true
+	}
+	return score > entries[len(entries)-1].Score
+}
+
+// Add inserts the entry and returns its 1-based rank, or 0 if it did not make
+// the table.
+func (t *HighScoreTable) Add(mode string, entry HighScoreEntry) int {
+	if !t.Qualifies(mode, entry.Score) {
+		return 0
+	}
+
+	entries := append(t.Modes[mode], entry)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Score > entries[j].Score
+	})
+	if len(entries) > MaxHighScores {
+		entries = entries[:MaxHighScores]
+	}
+	t.Modes[mode] = entries
+
+	for i := range entries {
+		if entries[i] == entry {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// NameEntry holds the state of the name prompt shown on the game over screen
+// when a run makes it into the high score table.
+type NameEntry struct {
+	Active bool
+	Name   []rune
+	Rank   int
+}
+
+// persistent reports whether the game should touch the player's autosave and
+// high scores. Bot, headless and network games never do.
+func (g *Game) persistent() bool {
+	return g.Bot == nil && g.HighScores != nil
+}
+
+func (g *Game) endGame() {
+	if g.IsGameOver {
+		return
+	}
+
+	g.IsGameOver = true
+	g.nameEntry = NameEntry{}
+	g.publish(GameOver{
+		At:    g.timer.elapsed,
+		Score: g.Scoring.Score,
+		Lines: g.Scoring.LinesCleared,
+		Level: g.Scoring.Level,
+	})
+
+	if g.Versus != nil {
+		g.Versus.onGameOver()
+	}
+
+	if !g.persistent() {
+		return
+	}
+
+	g.discardAutosave()
+
+	if g.HighScores.Qualifies(g.Mode, g.Scoring.Score) {
+		g.nameEntry.Active = true
+	}
+}
+
+func (g *Game) handleNameEntry(event Event) {
+	switch event.Action {
+	case "enter":
+		g.submitHighScore()
+	case "backspace":
+		if len(g.nameEntry.Name) > 0 {
+			g.nameEntry.Name = g.nameEntry.Name[:len(g.nameEntry.Name)-1]
+		}
+	case "space":
+		g.appendNameChar(' ')
+	default:
+		if event.Char != 0 {
+			g.appendNameChar(event.Char)
+		}
+	}
+}
+
+func (g *Game) appendNameChar(char rune) {
+	if len(g.nameEntry.Name) >= MaxNameLength || char < ' ' || char > '~' {
+		return
+	}
+	g.nameEntry.Name = append(g.nameEntry.Name, char)
+}
+
+func (g *Game) submitHighScore() {
+	name := strings.TrimSpace(string(g.nameEntry.Name))
+	if name == "" {
+		name = DefaultName
+	}
+
+	entry := HighScoreEntry{
+		Name:  name,
+		Score: g.Scoring.Score,
+		Lines: g.Scoring.LinesCleared,
+		Level: g.Scoring.Level,
+		Time:  g.timer.elapsed,
+		Seed:  g.Seed,
+		Date:  time.Now().UTC().Truncate(time.Second),
+	}
+
+	g.nameEntry.Active = false
+	g.nameEntry.Rank = g.HighScores.Add(g.Mode, entry)
+
+	if err := g.HighScores.Save(); err != nil {
+		GetLoggerInstance().Error("Could not save high scores", "err", err)
+		return
+	}
+
+	GetLoggerInstance().Info("High score saved", "name", name, "rank", g.nameEntry.Rank, "score", entry.Score)
+}
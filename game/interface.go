@@ -9,20 +9,47 @@ type Interface struct {
 	width      int
 	height     int
 	separators []int
+	compact    bool // No separators, the compact layout has no room for them
+	view       Viewport
+	ShowStats  bool
+	Title      string // Shown instead of the personal best, e.g. in split screen
+	Animations *Animations
 }
 
 func NewInterface(r *Renderer) *Interface {
-	// Calculate the right wall of the game field as the start of the interface
-	gameRightWallScreenX := GameFieldStartX + (GameFieldWidth * BlockWidth)
+	return NewInterfaceAt(r, DefaultViewport)
+}
 
-	return &Interface{
+// NewInterfaceAt creates the side panel for the field shown at view
+func NewInterfaceAt(r *Renderer, view Viewport) *Interface {
+	ui := &Interface{
 		renderer:   r,
-		interfaceX: gameRightWallScreenX + 2,
-		interfaceY: 1,
-		width:      r.ScreenWidth - gameRightWallScreenX - 3,
 		height:     GameFieldHeight,
-		separators: []int{2, 5, 8, 11},
+		separators: []int{2, 5, 8, 11, 14},
+		Animations: NewAnimations(),
+	}
+	ui.setGeometry(view, fullPanelWidth)
+
+	return ui
+}
+
+// place moves the panel to the index-th field of the layout, the renderer
+// calls it every frame so a resized terminal takes effect right away
+func (ui *Interface) place(layout Layout, index int) {
+	if index < len(layout.Views) {
+		ui.setGeometry(layout.Views[index], layout.PanelWidth)
 	}
+	ui.compact = layout.Compact
+}
+
+func (ui *Interface) setGeometry(view Viewport, panelWidth int) {
+	// The right wall of the game field is where the interface starts
+	gameRightWallScreenX := view.FieldX + (GameFieldWidth * BlockWidth)
+
+	ui.interfaceX = gameRightWallScreenX + 2
+	ui.interfaceY = view.FieldY
+	ui.width = panelWidth
+	ui.view = view
 }
 
 func (ui *Interface) Draw(game *Game) {
@@ -30,13 +57,49 @@ func (ui *Interface) Draw(game *Game) {
 	ui.DrawSeparators()
 
 	ui.DrawTimeSection(game.timer.elapsed / 1000)
-	ui.DrawLevelSection(game.Scoring.Level)
+	ui.DrawLevelSection(game.Scoring.Level, ui.Animations.LevelPulse(game.timer.elapsed))
 	ui.DrawLinesSection(game.Scoring.LinesCleared)
 	ui.DrawScoreSection(game.Scoring.Score)
-	ui.DrawNextSection(game.Player.NextPolyomino)
+
+	if ui.ShowStats {
+		ui.DrawStatsSection(game.Stats, game.timer.elapsed)
+	} else {
+		if game.Versus != nil {
+			ui.DrawOpponentSection(game)
+		} else if game.Puzzle != nil {
+			ui.DrawPuzzleSection(game)
+		} else if ui.Title != "" {
+			ui.DrawTitleSection(game)
+		} else {
+			ui.DrawBestSection(game.HighScores, game.Mode)
+		}
+		ui.DrawNextSection(game.Player.NextPolyomino)
+	}
+}
+
+// DrawOverlays draws the prompts and screens that cover the game field
+func (ui *Interface) DrawOverlays(game *Game) {
+	if game.Paused {
+		ui.DrawPausedScreen()
+	}
+
+	if game.pendingResume != nil {
+		ui.DrawResumePrompt(game.pendingResume)
+	}
 
 	if game.IsGameOver {
-		ui.DrawGameOverScreen()
+		ui.DrawStatsSummary(game.Stats, game.timer.elapsed)
+		if game.Versus != nil {
+			ui.DrawVersusResult(game.Versus)
+		} else if game.Puzzle != nil {
+			ui.DrawPuzzleResult(game.Puzzle)
+		} else {
+			ui.DrawGameOverScreen(game.nameEntry)
+		}
+	}
+
+	if game.console.Active {
+		ui.DrawConsole(game)
 	}
 }
 
@@ -51,6 +114,10 @@ func (ui *Interface) Clear() {
 }
 
 func (ui *Interface) DrawSeparators() {
+	if ui.compact {
+		return
+	}
+
 	for _, y := range ui.separators {
 		if ui.interfaceY+y < ui.renderer.ScreenHeight-1 {
 			ui.DrawSeparator(y)
@@ -60,22 +127,22 @@ func (ui *Interface) DrawSeparators() {
 
 func (ui *Interface) DrawSeparator(y int) {
 	// Draw a T-junction on the left side where the separator meets the game border
-	ui.renderer.Pixels[ui.interfaceY+y][ui.interfaceX-1] = ColoredPixel{Char: '╠', Color: "cyan"}
+	ui.renderer.Pixels[ui.interfaceY+y][ui.interfaceX-1] = ColoredPixel{Char: '╠', Color: ColorBorder}
 
 	// Draw the separator line
 	for x := 0; x < ui.width; x++ {
-		ui.renderer.Pixels[ui.interfaceY+y][ui.interfaceX+x] = ColoredPixel{Char: '═', Color: "cyan"}
+		ui.renderer.Pixels[ui.interfaceY+y][ui.interfaceX+x] = ColoredPixel{Char: '═', Color: ColorBorder}
 	}
 
 	// Draw a T-junction on the right side where the separator meets the outer border
 	if ui.interfaceX+ui.width < ui.renderer.ScreenWidth-1 {
-		ui.renderer.Pixels[ui.interfaceY+y][ui.interfaceX+ui.width] = ColoredPixel{Char: '╣', Color: "cyan"}
+		ui.renderer.Pixels[ui.interfaceY+y][ui.interfaceX+ui.width] = ColoredPixel{Char: '╣', Color: ColorBorder}
 	}
 }
 
 func (ui *Interface) DrawLabel(text string, yOffset int, color string) {
-	for i, char := range text {
-		if ui.interfaceX+i < ui.renderer.ScreenWidth-1 {
+	for i, char := range []rune(text) {
+		if i < ui.width && ui.interfaceX+i < ui.renderer.ScreenWidth-1 {
 			ui.renderer.Pixels[ui.interfaceY+yOffset][ui.interfaceX+i] = ColoredPixel{Char: char, Color: color}
 		}
 	}
@@ -86,8 +153,12 @@ func (ui *Interface) DrawTimeSection(seconds int64) {
 	ui.DrawLabel(fmt.Sprintf("%d", seconds), 1, "yellow")
 }
 
-func (ui *Interface) DrawLevelSection(level int) {
-	ui.DrawLabel("LEVEL", 3, "white")
+func (ui *Interface) DrawLevelSection(level int, pulse bool) {
+	if pulse {
+		ui.DrawLabel("LEVEL", 3, "yellow")
+	} else {
+		ui.DrawLabel("LEVEL", 3, "white")
+	}
 	ui.DrawLabel(fmt.Sprintf("%d", level), 4, "green")
 }
 
@@ -101,6 +172,61 @@ func (ui *Interface) DrawScoreSection(score int) {
 	ui.DrawLabel(fmt.Sprintf("%d", score), 10, "yellow")
 }
 
+func (ui *Interface) DrawTitleSection(game *Game) {
+	ui.DrawLabel(ui.Title, 12, "white")
+
+	if game.Garbage.Pending() > 0 {
+		ui.DrawLabel(fmt.Sprintf("INCOMING %d", game.Garbage.Pending()), 13, "red")
+	}
+}
+
+func (ui *Interface) DrawBestSection(scores *HighScoreTable, mode string) {
+	ui.DrawLabel("BEST", 12, "white")
+
+	best, ok := scores.Best(mode)
+	if !ok {
+		ui.DrawLabel("-", 13, "magenta")
+		return
+	}
+
+	ui.DrawLabel(fmt.Sprintf("%d %s", best.Score, best.Name), 13, "magenta")
+}
+
+// DrawStatsSection replaces the BEST and NEXT sections with the live stats
+func (ui *Interface) DrawStatsSection(stats *Statistics, elapsed int64) {
+	firstRow := ui.separators[3] + 1
+
+	// The stats panel spans the rows of the sections it replaces
+	if !ui.compact {
+		ui.renderer.Pixels[ui.interfaceY+ui.separators[4]][ui.interfaceX-1] = ColoredPixel{Char: '║', Color: ColorBorder}
+		ui.renderer.Pixels[ui.interfaceY+ui.separators[4]][ui.interfaceX+ui.width] = ColoredPixel{Char: '║', Color: ColorBorder}
+		for x := 0; x < ui.width; x++ {
+			ui.renderer.Pixels[ui.interfaceY+ui.separators[4]][ui.interfaceX+x] = ColoredPixel{Char: ' ', Color: ""}
+		}
+	}
+
+	ui.DrawLabel("STATS", firstRow, "white")
+	for i, line := range stats.Lines(elapsed) {
+		if firstRow+1+i >= ui.height {
+			break
+		}
+		ui.DrawLabel(line, firstRow+1+i, "cyan")
+	}
+}
+
+func (ui *Interface) DrawOpponentSection(game *Game) {
+	session := game.Versus
+
+	ui.DrawLabel("OPPONENT", 12, "white")
+
+	if !session.Opponent.Updated {
+		ui.DrawLabel("waiting", 13, "magenta")
+		return
+	}
+
+	ui.DrawLabel(fmt.Sprintf("%d H%d IN%d", session.Opponent.Score, session.Opponent.Height, game.Garbage.Pending()), 13, "magenta")
+}
+
 func (ui *Interface) DrawFieldInfoSection(width, height int) {
 	ui.DrawLabel("FIELD", 12, "white")
 	ui.DrawLabel(fmt.Sprintf("%dx%d", width, height), 13, "cyan")
@@ -110,6 +236,31 @@ func (ui *Interface) DrawNextSection(next *Polyomino) {
 	displayWidth := 8
 	displayHeight := 8
 
+	// Calculate polyomino bounds
+	var minX, maxX, minY, maxY int
+	if next != nil {
+		for _, block := range next.Blocks {
+			minX = min(minX, block.Position.X)
+			maxX = max(maxX, block.Position.X)
+			minY = min(minY, block.Position.Y)
+			maxY = max(maxY, block.Position.Y)
+		}
+	}
+	width := maxX - minX + 1
+	height := maxY - minY + 1
+
+	// Big pieces are shrunk until they fit: first to one character per block,
+	// then also to two rows per character. Pieces wider than the box widen it
+	// up to the panel.
+	cellWidth, rowsPerChar := 2, 1
+	if width*2 > displayWidth || height > displayHeight-1 {
+		cellWidth = 1
+		displayWidth = min(max(displayWidth, width), ui.width-2)
+	}
+	if height > displayHeight-1 {
+		rowsPerChar = 2
+	}
+
 	// Calculate center position
 	startX := ui.interfaceX + (ui.width-displayWidth)/2
 	startY := ui.interfaceY + ui.separators[len(ui.separators)-1] + 2
@@ -121,24 +272,24 @@ func (ui *Interface) DrawNextSection(next *Polyomino) {
 
 	// Draw a bordered box for the NEXT block
 	// Top border
-	ui.renderer.Pixels[startY][startX-1] = ColoredPixel{Char: '╔', Color: "cyan"} // Top-left corner
+	ui.renderer.Pixels[startY][startX-1] = ColoredPixel{Char: '╔', Color: ColorBorder} // Top-left corner
 	for x := 0; x < displayWidth; x++ {
-		ui.renderer.Pixels[startY][startX+x] = ColoredPixel{Char: '═', Color: "cyan"}
+		ui.renderer.Pixels[startY][startX+x] = ColoredPixel{Char: '═', Color: ColorBorder}
 	}
-	ui.renderer.Pixels[startY][startX+displayWidth] = ColoredPixel{Char: '╗', Color: "cyan"} // Top-right corner
+	ui.renderer.Pixels[startY][startX+displayWidth] = ColoredPixel{Char: '╗', Color: ColorBorder} // Top-right corner
 
 	// Side borders
 	for y := 1; y < displayHeight; y++ {
-		ui.renderer.Pixels[startY+y][startX-1] = ColoredPixel{Char: '║', Color: "cyan"}            // Left border
-		ui.renderer.Pixels[startY+y][startX+displayWidth] = ColoredPixel{Char: '║', Color: "cyan"} // Right border
+		ui.renderer.Pixels[startY+y][startX-1] = ColoredPixel{Char: '║', Color: ColorBorder}            // Left border
+		ui.renderer.Pixels[startY+y][startX+displayWidth] = ColoredPixel{Char: '║', Color: ColorBorder} // Right border
 	}
 
 	// Bottom border
-	ui.renderer.Pixels[startY+displayHeight][startX-1] = ColoredPixel{Char: '╚', Color: "cyan"} // Bottom-left corner
+	ui.renderer.Pixels[startY+displayHeight][startX-1] = ColoredPixel{Char: '╚', Color: ColorBorder} // Bottom-left corner
 	for x := 0; x < displayWidth; x++ {
-		ui.renderer.Pixels[startY+displayHeight][startX+x] = ColoredPixel{Char: '═', Color: "cyan"}
+		ui.renderer.Pixels[startY+displayHeight][startX+x] = ColoredPixel{Char: '═', Color: ColorBorder}
 	}
-	ui.renderer.Pixels[startY+displayHeight][startX+displayWidth] = ColoredPixel{Char: '╝', Color: "cyan"} // Bottom-right corner
+	ui.renderer.Pixels[startY+displayHeight][startX+displayWidth] = ColoredPixel{Char: '╝', Color: ColorBorder} // Bottom-right corner
 
 	// Clear the display area (inside the border)
 	for y := 0; y < displayHeight-1; y++ {
@@ -151,45 +302,40 @@ func (ui *Interface) DrawNextSection(next *Polyomino) {
 		return
 	}
 
-	// Calculate polyomino bounds
-	var minX, maxX, minY, maxY int
-	for _, block := range next.Blocks {
-		if block.Position.X < minX {
-			minX = block.Position.X
-		}
-		if block.Position.X > maxX {
-			maxX = block.Position.X
-		}
-		if block.Position.Y < minY {
-			minY = block.Position.Y
-		}
-		if block.Position.Y > maxY {
-			maxY = block.Position.Y
-		}
-	}
+	// Calculate centering offsets - adjusted for the border and the cell size
+	offsetX := (displayWidth - (width * cellWidth)) / 2
+	offsetY := (displayHeight - 1 - (height+rowsPerChar-1)/rowsPerChar) / 2
 
-	width := maxX - minX + 1
-	height := maxY - minY + 1
-
-	// Calculate centering offsets - adjusted for the border and double-width blocks
-	offsetX := (displayWidth - (width * 2)) / 2
-	offsetY := (displayHeight - height) / 2
+	// Halves of the shrunk characters: 1 is the top row, 2 the bottom one
+	halves := make(map[Position]int)
+	colors := make(map[Position]string)
 
 	// Draw the polyomino centered
 	for _, block := range next.Blocks {
-		baseX := (block.Position.X-minX)*2 + offsetX
-		baseY := block.Position.Y - minY + offsetY
+		baseX := (block.Position.X-minX)*cellWidth + offsetX
+		baseY := (block.Position.Y-minY)/rowsPerChar + offsetY
 
-		if baseY >= 0 && baseY < displayHeight {
-			// First block character
-			if baseX >= 0 && baseX < displayWidth {
-				ui.renderer.Pixels[startY+baseY+1][startX+baseX] = ColoredPixel{Char: '█', Color: block.Color}
-			}
+		if baseY < 0 || baseY >= displayHeight-1 || baseX < 0 || baseX+cellWidth > displayWidth {
+			continue
+		}
 
+		if cellWidth == 2 {
+			ui.renderer.Pixels[startY+baseY+1][startX+baseX] = ColoredPixel{Char: ui.renderer.glyphs.Block[0], Color: piecePixel + block.Color}
 			// Second block character to make it double width
-			if baseX+1 >= 0 && baseX+1 < displayWidth {
-				ui.renderer.Pixels[startY+baseY+1][startX+baseX+1] = ColoredPixel{Char: '█', Color: block.Color}
-			}
+			ui.renderer.Pixels[startY+baseY+1][startX+baseX+1] = ColoredPixel{Char: ui.renderer.glyphs.Block[1], Color: piecePixel + block.Color}
+			continue
 		}
+
+		at := Position{X: baseX, Y: baseY}
+		if rowsPerChar == 1 {
+			halves[at] = 3
+		} else {
+			halves[at] |= 1 << ((block.Position.Y - minY) % 2)
+		}
+		colors[at] = block.Color
+	}
+
+	for at, half := range halves {
+		ui.renderer.Pixels[startY+at.Y+1][startX+at.X] = ColoredPixel{Char: ui.renderer.glyphs.Small[half-1], Color: piecePixel + colors[at]}
 	}
 }
@@ -0,0 +1,79 @@
+package game
+
+// Layout is where the fields and side panels go for a given terminal size
+type Layout struct {
+	ScreenWidth  int
+	ScreenHeight int
+	Views        []Viewport
+	PanelWidth   int
+	Compact      bool // Narrow panel, no outer border and no panel separators
+
+	// TooSmall means not even the compact layout fits, the screen only asks
+	// for a terminal of at least MinWidth x MinHeight
+	TooSmall  bool
+	MinWidth  int
+	MinHeight int
+}
+
+const (
+	fullPanelWidth    = ScreenUnitWidth - GameFieldStartX - (GameFieldWidth * BlockWidth) - 2
+	compactPanelWidth = 12
+
+	fullScreenHeight    = GameFieldEndY + 3   // Floor, a spare row and the border
+	compactScreenHeight = GameFieldHeight + 1 // Only the field and its floor
+)
+
+// NewLayout picks the roomiest layout that fits fields boards side by side
+// in a width x height terminal. A size of 0 means unknown and always gets the
+// full layout.
+func NewLayout(fields, width, height int) Layout {
+	full := layoutFor(fields, fullPanelWidth, fullScreenHeight, GameFieldStartY)
+	if width <= 0 || height <= 0 || full.fits(width, height) {
+		return full
+	}
+
+	compact := layoutFor(fields, compactPanelWidth, compactScreenHeight, 0)
+	compact.Compact = true
+	if compact.fits(width, height) {
+		return compact
+	}
+
+	return Layout{
+		ScreenWidth:  max(width, 1),
+		ScreenHeight: max(height, 1),
+		Views:        compact.Views,
+		PanelWidth:   compactPanelWidth,
+		Compact:      true,
+		TooSmall:     true,
+		MinWidth:     compact.ScreenWidth,
+		MinHeight:    compact.ScreenHeight,
+	}
+}
+
+func layoutFor(fields, panelWidth, screenHeight, fieldY int) Layout {
+	unitWidth := GameFieldStartX + (GameFieldWidth * BlockWidth) + 2 + panelWidth
+
+	layout := Layout{
+		ScreenWidth:  fields*unitWidth + 1,
+		ScreenHeight: screenHeight,
+		PanelWidth:   panelWidth,
+	}
+	for i := 0; i < fields; i++ {
+		layout.Views = append(layout.Views, Viewport{
+			FieldX: i*unitWidth + GameFieldStartX,
+			FieldY: fieldY,
+		})
+	}
+	layout.MinWidth, layout.MinHeight = layout.ScreenWidth, layout.ScreenHeight
+	return layout
+}
+
+func (l Layout) fits(width, height int) bool {
+	return l.ScreenWidth <= width && l.ScreenHeight <= height
+}
+
+func (l Layout) sameShape(other Layout) bool {
+	return l.ScreenWidth == other.ScreenWidth && l.ScreenHeight == other.ScreenHeight &&
+		l.PanelWidth == other.PanelWidth && len(l.Views) == len(other.Views) &&
+		l.Compact == other.Compact && l.TooSmall == other.TooSmall && l.MinWidth == other.MinWidth && l.MinHeight == other.MinHeight
+}
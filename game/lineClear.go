@@ -1,129 +1,60 @@
 package game
 
-import "fmt"
+import "sort"
 
-func (g *Game) CheckLineClear() {
+// CheckLineClear removes full rows and returns how many were cleared. With
+// sticky or cascade gravity the falling blocks can fill more rows, those are
+// cleared as a chain and every link scores more than the one before.
+func (g *Game) CheckLineClear() int {
 	clearedLines := 0
 
-	blocksInRow := make(map[int]int)
-
-	for _, block := range g.placedBlocks {
-		blocksInRow[block.Position.Y]++
-	}
-
-	fullRows := []int{}
-	for y, count := range blocksInRow {
-		if count >= GameFieldWidth {
-			fullRows = append(fullRows, y)
-			clearedLines++
+	for chain := 1; ; chain++ {
+		fullRows := g.fullRows()
+		if len(fullRows) == 0 {
+			return clearedLines
 		}
-	}
 
-	if clearedLines == 0 {
-		return
-	}
+		// The animation shows the rows going from the stack as it was
+		stack := append([]Block(nil), g.placedBlocks...)
+		g.removeRows(fullRows)
+		clearedLines += len(fullRows)
 
-	newPlacedBlocks := []Block{}
-	for _, block := range g.placedBlocks {
-		isInFullRow := false
-		for _, fullY := range fullRows {
-			if block.Position.Y == fullY {
-				isInFullRow = true
-				break
-			}
-		}
+		previousLevel := g.Scoring.Level
+		g.Scoring.AddChainLines(len(fullRows), chain)
+		g.Stats.RecordClear(len(fullRows))
 
-		if !isInFullRow {
-			newPlacedBlocks = append(newPlacedBlocks, block)
+		g.publish(LinesCleared{
+			At:         g.timer.elapsed,
+			Lines:      len(fullRows),
+			Rows:       fullRows,
+			Chain:      chain,
+			TotalLines: g.Scoring.LinesCleared,
+			Score:      g.Scoring.Score,
+			Stack:      stack,
+		})
+		if g.Scoring.Level > previousLevel {
+			g.publish(LevelUp{At: g.timer.elapsed, Level: g.Scoring.Level})
 		}
-	}
 
-	for _, fullY := range fullRows {
-		for i := range newPlacedBlocks {
-			if newPlacedBlocks[i].Position.Y < fullY {
-				newPlacedBlocks[i].Position.Y++
-			}
+		if chain > 1 {
+			GetLoggerInstance().Info("Chain clear", "chain", chain, "lines", len(fullRows))
 		}
 	}
-
-	g.placedBlocks = newPlacedBlocks
-
-	droppedRows := g.DropPlacedBlocks()
-
-	g.Scoring.AddLines(clearedLines)
-
-	GetLoggerInstance().Log(fmt.Sprintf("Cleared %d lines! Dropped blocks by %d rows. Score: %d, Level: %d",
-		clearedLines, droppedRows, g.Scoring.Score, g.Scoring.Level))
 }
 
-func (g *Game) DropPlacedBlocks() int {
-	if len(g.placedBlocks) == 0 {
-		return 0
+// fullRows returns the rows of the stack without a gap, top to bottom
+func (g *Game) fullRows() []int {
+	blocksInRow := make(map[int]int)
+	for _, block := range g.placedBlocks {
+		blocksInRow[block.Position.Y]++
 	}
 
-	movesMade := 0
-	somethingMoved := true
-
-	for somethingMoved {
-		somethingMoved = false
-
-		occupiedSpaces := make(map[string]bool)
-		for _, block := range g.placedBlocks {
-			key := fmt.Sprintf("%d,%d", block.Position.X, block.Position.Y)
-			occupiedSpaces[key] = true
-		}
-
-		anyBlockNearBottom := false
-		for _, block := range g.placedBlocks {
-			if block.Position.Y >= GameFieldHeight-2 {
-				anyBlockNearBottom = true
-				break
-			}
-		}
-
-		if anyBlockNearBottom {
-			break
-		}
-
-		canMove := make([]bool, len(g.placedBlocks))
-
-		for i := range g.placedBlocks {
-			blockX := g.placedBlocks[i].Position.X
-			blockY := g.placedBlocks[i].Position.Y
-
-			if blockY >= GameFieldHeight-1 {
-				canMove[i] = false
-				continue
-			}
-
-			belowKey := fmt.Sprintf("%d,%d", blockX, blockY+1)
-			if occupiedSpaces[belowKey] {
-				canMove[i] = false
-				continue
-			}
-
-			canMove[i] = true
-			somethingMoved = true
-		}
-
-		if somethingMoved {
-			for i, block := range g.placedBlocks {
-				if canMove[i] {
-					key := fmt.Sprintf("%d,%d", block.Position.X, block.Position.Y)
-					delete(occupiedSpaces, key)
-				}
-			}
-
-			for i := range g.placedBlocks {
-				if canMove[i] {
-					g.placedBlocks[i].Position.Y++
-				}
-			}
-
-			movesMade++
+	rows := []int{}
+	for y, count := range blocksInRow {
+		if count >= GameFieldWidth {
+			rows = append(rows, y)
 		}
 	}
-
-	GetLoggerInstance().Log(fmt.Sprintf("Dropped blocks by %d rows", movesMade))
-	return movesMade
+	sort.Ints(rows)
+	return rows
 }
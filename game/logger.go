@@ -1,11 +1,27 @@
 package game
 
-import "sync"
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
 
+// LogLevels is the order F2 cycles through
+var LogLevels = []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn, slog.LevelError}
+
+// Logger writes leveled, structured records to an optional rotating log file
+// and keeps the most recent ones for the on-screen log panel.
 type Logger struct {
-	enabled bool
-	size    int
-	logs    []string
+	mu      sync.Mutex
+	enabled atomic.Bool
+	visible atomic.Bool
+	level   *slog.LevelVar
+	screen  *ringBuffer
+	file    *rotatingFile
+	slog    *slog.Logger
 }
 
 var loggerInstance *Logger
@@ -14,35 +30,228 @@ var loggerOnce sync.Once
 func GetLoggerInstance() *Logger {
 	loggerOnce.Do(func() {
 		loggerInstance = &Logger{
-			enabled: true,
-			size:    100,
-			logs:    make([]string, 0, 100),
+			level:  new(slog.LevelVar),
+			screen: &ringBuffer{size: 100},
 		}
+		loggerInstance.enabled.Store(true)
+		loggerInstance.level.Set(slog.LevelInfo)
+		loggerInstance.rebuild()
 	})
 	return loggerInstance
 }
 
-func (l *Logger) Log(log string) {
-	if !l.enabled {
+// rebuild recreates the slog logger after the file sink changed. Callers hold
+// l.mu, except the constructor.
+func (l *Logger) rebuild() {
+	handlers := fanoutHandler{
+		slog.NewTextHandler(l.screen, &slog.HandlerOptions{
+			Level: l.level,
+			ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+				// The panel is redrawn every frame, timestamps only waste columns
+				if len(groups) == 0 && attr.Key == slog.TimeKey {
+					return slog.Attr{}
+				}
+				return attr
+			},
+		}),
+	}
+	if l.file != nil {
+		handlers = append(handlers, slog.NewTextHandler(l.file, &slog.HandlerOptions{Level: l.level}))
+	}
+	l.slog = slog.New(handlers)
+}
+
+func (l *Logger) log(level slog.Level, msg string, args ...any) {
+	if !l.enabled.Load() {
 		return
 	}
-	if len(l.logs) >= l.size {
-		l.logs = l.logs[1:]
+
+	l.mu.Lock()
+	logger := l.slog
+	l.mu.Unlock()
+
+	logger.Log(context.Background(), level, msg, args...)
+}
+
+func (l *Logger) Debug(msg string, args ...any) { l.log(slog.LevelDebug, msg, args...) }
+func (l *Logger) Info(msg string, args ...any)  { l.log(slog.LevelInfo, msg, args...) }
+func (l *Logger) Warn(msg string, args ...any)  { l.log(slog.LevelWarn, msg, args...) }
+func (l *Logger) Error(msg string, args ...any) { l.log(slog.LevelError, msg, args...) }
+
+// SetEnabled turns all logging off, e.g. for headless simulations
+func (l *Logger) SetEnabled(enabled bool) {
+	l.enabled.Store(enabled)
+}
+
+func (l *Logger) SetLevel(level slog.Level) {
+	l.level.Set(level)
+}
+
+func (l *Logger) Level() slog.Level {
+	return l.level.Level()
+}
+
+// CycleLevel moves to the next level in LogLevels, wrapping around
+func (l *Logger) CycleLevel() slog.Level {
+	current := l.level.Level()
+	next := LogLevels[0]
+	for i, level := range LogLevels {
+		if level == current {
+			next = LogLevels[(i+1)%len(LogLevels)]
+			break
+		}
+	}
+
+	l.level.Set(next)
+	// Logged at the new level itself so the change always shows up
+	l.log(next, "Log level changed", "level", next)
+	return next
+}
+
+// ParseLogLevel accepts debug, info, warn or error (any case)
+func ParseLogLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q", name)
 	}
-	l.logs = append(l.logs, log)
+	return level, nil
+}
+
+// SetFile sends records to a rotating log file at path as well
+func (l *Logger) SetFile(path string) error {
+	file, err := openRotatingFile(path, logFileMaxSize, logFileBackups)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	previous := l.file
+	l.file = file
+	l.rebuild()
+	l.mu.Unlock()
+
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// Close flushes and closes the log file, if any
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	err := l.file.Close()
+	l.file = nil
+	l.rebuild()
+	return err
+}
+
+// ToggleVisible shows or hides the on-screen log panel
+func (l *Logger) ToggleVisible() bool {
+	visible := !l.visible.Load()
+	l.visible.Store(visible)
+	return visible
+}
+
+func (l *Logger) Visible() bool {
+	return l.visible.Load()
 }
 
 func (l *Logger) PrintLogs(maxLogs int) {
-	if !l.enabled {
+	if !l.visible.Load() {
 		return
 	}
-	var limit int
-	if maxLogs > len(l.logs) {
-		limit = len(l.logs)
-	} else {
-		limit = maxLogs
+
+	println(fmt.Sprintf("-- log (%s, F2 changes level, L hides) --", l.level.Level()))
+	for _, line := range l.screen.Last(maxLogs) {
+		println(line)
+	}
+}
+
+// ScreenLines counts the lines the log panel has received, it changes
+// whenever PrintLogs would print something new
+func (l *Logger) ScreenLines() uint64 {
+	return l.screen.Written()
+}
+
+// ringBuffer keeps the last size lines written to it
+type ringBuffer struct {
+	mu      sync.Mutex
+	size    int
+	lines   []string
+	written uint64 // Lines written in total, including the dropped ones
+}
+
+func (b *ringBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, line := range strings.Split(strings.TrimRight(string(p), "\n"), "\n") {
+		if len(b.lines) >= b.size {
+			b.lines = b.lines[1:]
+		}
+		b.lines = append(b.lines, line)
+		b.written++
+	}
+	return len(p), nil
+}
+
+func (b *ringBuffer) Written() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.written
+}
+
+func (b *ringBuffer) Last(n int) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n = max(0, min(n, len(b.lines)))
+	return append([]string(nil), b.lines[len(b.lines)-n:]...)
+}
+
+// fanoutHandler hands every record to each of its handlers
+type fanoutHandler []slog.Handler
+
+func (h fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var firstErr error
+	for _, handler := range h {
+		if !handler.Enabled(ctx, record.Level) {
+			continue
+		}
+		if err := handler.Handle(ctx, record.Clone()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (h fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithAttrs(attrs)
 	}
-	for i := len(l.logs) - limit; i < len(l.logs); i++ {
-		println(l.logs[i])
+	return handlers
+}
+
+func (h fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make(fanoutHandler, len(h))
+	for i, handler := range h {
+		handlers[i] = handler.WithGroup(name)
 	}
+	return handlers
 }
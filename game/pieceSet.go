@@ -0,0 +1,227 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// PieceSet replaces the random polyominoes with a fixed list of shapes.
+// Piece sets are JSON files, e.g.
+//
+//	{
+//	  "name": "my-set",
+//	  "pieces": [
+//	    {"name": "T", "shape": ["#@#", ".#."], "color": "magenta"},
+//	    {"name": "I", "shape": ["#@##"], "weight": 2},
+//	    {"shape": ["##", "#."], "spawnRotation": 1}
+//	  ]
+//	}
+//
+// A shape is drawn as rows of text: '#' is a block, '@' a block the piece
+// rotates around and '.' or ' ' is empty. Without an '@' the piece rotates
+// around its middle. Color is one of the piece colors, random when left out.
+// spawnRotation turns the piece that many times, the way the rotate key does,
+// before it spawns. weight is how likely the piece is relative to the others,
+// 1 when left out.
+type PieceSet struct {
+	Name   string          `json:"name"`
+	Pieces []PieceSetPiece `json:"pieces"`
+}
+
+type PieceSetPiece struct {
+	Name          string   `json:"name,omitempty"`
+	Shape         []string `json:"shape"`
+	Color         string   `json:"color,omitempty"`
+	SpawnRotation int      `json:"spawnRotation,omitempty"`
+	Weight        int      `json:"weight,omitempty"`
+}
+
+// BuiltinPieceSets can be picked by name with --piece-set
+var BuiltinPieceSets = map[string]PieceSet{
+	"tetrominoes": {
+		Name: "tetrominoes",
+		Pieces: []PieceSetPiece{
+			{Name: "I", Shape: []string{"#@##"}, Color: "cyan"},
+			{Name: "O", Shape: []string{"@#", "##"}, Color: "yellow"},
+			{Name: "T", Shape: []string{"#@#", ".#."}, Color: "magenta"},
+			{Name: "S", Shape: []string{".@#", "##."}, Color: "green"},
+			{Name: "Z", Shape: []string{"#@.", ".##"}, Color: "red"},
+			{Name: "J", Shape: []string{"#@#", "..#"}, Color: "blue"},
+			{Name: "L", Shape: []string{"#@#", "#.."}, Color: "white"},
+		},
+	},
+	// The 18 one-sided pentominoes, pieces cannot be flipped so mirror images
+	// are pieces of their own
+	"pentominoes": {
+		Name: "pentominoes",
+		Pieces: []PieceSetPiece{
+			{Name: "F", Shape: []string{".##", "#@.", ".#."}},
+			{Name: "F'", Shape: []string{"##.", ".@#", ".#."}},
+			{Name: "I", Shape: []string{"##@##"}},
+			{Name: "L", Shape: []string{"#@##", "#..."}},
+			{Name: "L'", Shape: []string{"##@#", "...#"}},
+			{Name: "N", Shape: []string{"##..", ".#@#"}},
+			{Name: "N'", Shape: []string{"..##", "#@#."}},
+			{Name: "P", Shape: []string{"##", "@#", "#."}},
+			{Name: "P'", Shape: []string{"##", "#@", ".#"}},
+			{Name: "T", Shape: []string{"###", ".@.", ".#."}},
+			{Name: "U", Shape: []string{"#.#", "#@#"}},
+			{Name: "V", Shape: []string{"#..", "#..", "@##"}},
+			{Name: "W", Shape: []string{"#..", "@#.", ".##"}},
+			{Name: "X", Shape: []string{".#.", "#@#", ".#."}},
+			{Name: "Y", Shape: []string{"#@##", ".#.."}},
+			{Name: "Y'", Shape: []string{"##@#", "..#."}},
+			{Name: "Z", Shape: []string{"##.", ".@.", ".##"}},
+			{Name: "Z'", Shape: []string{".##", ".@.", "##."}},
+		},
+	},
+}
+
+// PieceSetNames lists the built-in piece sets in order
+func PieceSetNames() []string {
+	names := make([]string, 0, len(BuiltinPieceSets))
+	for name := range BuiltinPieceSets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadPieceSet returns a built-in piece set by name or reads a piece set
+// file, an empty name means random polyominoes and returns nil
+func LoadPieceSet(nameOrPath string) (*PieceSet, error) {
+	if nameOrPath == "" {
+		return nil, nil
+	}
+
+	if set, ok := BuiltinPieceSets[nameOrPath]; ok {
+		return &set, nil
+	}
+
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var set PieceSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%s: %w", nameOrPath, err)
+	}
+	if err := set.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", nameOrPath, err)
+	}
+	return &set, nil
+}
+
+// Validate checks every shape, so a bad file fails at load time and not when
+// the piece first comes up
+func (s *PieceSet) Validate() error {
+	if len(s.Pieces) == 0 {
+		return errors.New("piece set has no pieces")
+	}
+
+	for i, piece := range s.Pieces {
+		name := piece.Name
+		if name == "" {
+			name = fmt.Sprintf("%d", i+1)
+		}
+
+		if _, err := piece.cells(); err != nil {
+			return fmt.Errorf("piece %s: %w", name, err)
+		}
+		if piece.Color != "" && !isPieceColor(piece.Color) {
+			return fmt.Errorf("piece %s: unknown color %q", name, piece.Color)
+		}
+		if piece.Weight < 0 {
+			return fmt.Errorf("piece %s: weight must not be negative", name)
+		}
+		if piece.SpawnRotation < 0 || piece.SpawnRotation > 3 {
+			return fmt.Errorf("piece %s: spawnRotation must be 0 to 3", name)
+		}
+	}
+	return nil
+}
+
+// Generate draws a piece, each one as likely as its weight says
+func (s *PieceSet) Generate(rng *Randomizer) *Polyomino {
+	total := 0
+	for _, piece := range s.Pieces {
+		total += piece.weight()
+	}
+
+	pick := rng.Intn(total)
+	for _, piece := range s.Pieces {
+		pick -= piece.weight()
+		if pick < 0 {
+			return piece.polyomino(rng)
+		}
+	}
+	return nil
+}
+
+func (p PieceSetPiece) weight() int {
+	if p.Weight == 0 {
+		return 1
+	}
+	return p.Weight
+}
+
+func (p PieceSetPiece) polyomino(rng *Randomizer) *Polyomino {
+	cells, _ := p.cells()
+
+	color := p.Color
+	if color == "" {
+		color = PieceColors[rng.Intn(len(PieceColors))]
+	}
+	return NewSpawnPolyomino(cells, color)
+}
+
+// cells parses the shape into block offsets around the rotation point, in
+// spawn orientation
+func (p PieceSetPiece) cells() ([]Position, error) {
+	var blocks []Block
+	var center *Position
+	for y, row := range p.Shape {
+		for x, c := range row {
+			switch c {
+			case '#':
+				blocks = append(blocks, Block{Position: Position{X: x, Y: y}})
+			case '@':
+				if center != nil {
+					return nil, errors.New("shape has more than one @")
+				}
+				center = &Position{X: x, Y: y}
+				blocks = append(blocks, Block{Position: *center})
+			case '.', ' ':
+			default:
+				return nil, fmt.Errorf("unknown character %q in shape", c)
+			}
+		}
+	}
+
+	positions := make([]Position, len(blocks))
+	for i, block := range blocks {
+		positions[i] = block.Position
+	}
+	if err := checkShape(positions); err != nil {
+		return nil, err
+	}
+
+	if center == nil {
+		rotationPoint := CalculateRotationPoint(blocks)
+		center = &rotationPoint
+	}
+
+	cells := make([]Position, len(blocks))
+	for i, block := range blocks {
+		cells[i] = Position{X: block.X - center.X, Y: block.Y - center.Y}
+		// Same turn as Polyomino.Rotate(true) around 0,0
+		for r := 0; r < p.SpawnRotation; r++ {
+			cells[i] = Position{X: cells[i].Y, Y: -cells[i].X}
+		}
+	}
+	return cells, nil
+}
@@ -0,0 +1,168 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinPieceSetsAreValid(t *testing.T) {
+	for _, name := range PieceSetNames() {
+		set := BuiltinPieceSets[name]
+		if err := set.Validate(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestPieceSetPieceCells(t *testing.T) {
+	tests := []struct {
+		name    string
+		piece   PieceSetPiece
+		want    []Position
+		wantErr string // "" for a valid shape
+	}{
+		{
+			name:  "around the @",
+			piece: PieceSetPiece{Shape: []string{"#@#"}},
+			want:  []Position{{X: -1, Y: 0}, {X: 0, Y: 0}, {X: 1, Y: 0}},
+		},
+		{
+			name:  "spaces are empty",
+			piece: PieceSetPiece{Shape: []string{"@ ", "# "}},
+			want:  []Position{{X: 0, Y: 0}, {X: 0, Y: 1}},
+		},
+		{
+			name:  "spawn rotation",
+			piece: PieceSetPiece{Shape: []string{"#@#"}, SpawnRotation: 1},
+			want:  []Position{{X: 0, Y: 1}, {X: 0, Y: 0}, {X: 0, Y: -1}},
+		},
+		{
+			name:  "four turns come back round",
+			piece: PieceSetPiece{Shape: []string{"#@#"}, SpawnRotation: 4},
+			want:  []Position{{X: -1, Y: 0}, {X: 0, Y: 0}, {X: 1, Y: 0}},
+		},
+		{name: "no blocks", piece: PieceSetPiece{Shape: []string{"..", ".."}}, wantErr: "no blocks"},
+		{name: "no shape", piece: PieceSetPiece{}, wantErr: "no blocks"},
+		{name: "two centers", piece: PieceSetPiece{Shape: []string{"@@"}}, wantErr: "more than one @"},
+		{name: "unknown character", piece: PieceSetPiece{Shape: []string{"#x"}}, wantErr: "unknown character"},
+		{name: "apart", piece: PieceSetPiece{Shape: []string{"#.#"}}, wantErr: "not connected"},
+		{name: "corner to corner", piece: PieceSetPiece{Shape: []string{"#.", ".#"}}, wantErr: "not connected"},
+		{name: "too big", piece: PieceSetPiece{Shape: []string{strings.Repeat("#", MaxPieceSize+1)}}, wantErr: "at most"},
+		{name: "biggest allowed", piece: PieceSetPiece{Shape: []string{strings.Repeat("#", MaxPieceSize)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells, err := tt.piece.cells()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatalf("cells() = %v, want no error", err)
+			case tt.wantErr != "" && err == nil:
+				t.Fatalf("cells() passed, want an error about %q", tt.wantErr)
+			case err != nil && !strings.Contains(err.Error(), tt.wantErr):
+				t.Fatalf("cells() = %v, want an error about %q", err, tt.wantErr)
+			}
+			if tt.want != nil && !slices.Equal(cells, tt.want) {
+				t.Errorf("cells() = %v, want %v", cells, tt.want)
+			}
+		})
+	}
+}
+
+func TestPieceSetValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		pieces  []PieceSetPiece
+		wantErr string // "" for a valid set
+	}{
+		{"valid", []PieceSetPiece{{Shape: []string{"#@"}, Color: "red", Weight: 2, SpawnRotation: 3}}, ""},
+		{"no pieces", nil, "no pieces"},
+		{"bad shape", []PieceSetPiece{{Name: "bad", Shape: []string{"#.#"}}}, "piece bad: shape is not connected"},
+		{"unnamed pieces by number", []PieceSetPiece{{Shape: []string{"#"}}, {Shape: []string{"?"}}}, "piece 2:"},
+		{"unknown color", []PieceSetPiece{{Shape: []string{"#"}, Color: "pink"}}, "unknown color"},
+		{"negative weight", []PieceSetPiece{{Shape: []string{"#"}, Weight: -1}}, "weight"},
+		{"negative spawn rotation", []PieceSetPiece{{Shape: []string{"#"}, SpawnRotation: -1}}, "spawnRotation"},
+		{"spawn rotation past 3", []PieceSetPiece{{Shape: []string{"#"}, SpawnRotation: 4}}, "spawnRotation"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := PieceSet{Name: tt.name, Pieces: tt.pieces}
+			err := set.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() = %v, want no error", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("Validate() passed, want an error about %q", tt.wantErr)
+			case err != nil && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("Validate() = %v, want an error about %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadPieceSet(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name     string
+		arg      string
+		wantName string // "" when no set comes back
+		wantErr  bool
+	}{
+		{"random polyominoes", "", "", false},
+		{"builtin", "pentominoes", "pentominoes", false},
+		{"file", write("set.json", `{"name": "dominoes", "pieces": [{"shape": ["@#"]}]}`), "dominoes", false},
+		{"invalid file", write("bad.json", `{"name": "bad", "pieces": [{"shape": ["#.#"]}]}`), "", true},
+		{"not json", write("text.json", `pieces`), "", true},
+		{"missing file", filepath.Join(dir, "missing.json"), "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := LoadPieceSet(tt.arg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPieceSet error = %v, want error %v", err, tt.wantErr)
+			}
+
+			name := ""
+			if set != nil {
+				name = set.Name
+			}
+			if name != tt.wantName {
+				t.Errorf("loaded set %q, want %q", name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestPieceSetGenerateFollowsWeights(t *testing.T) {
+	set := PieceSet{Pieces: []PieceSetPiece{
+		{Shape: []string{"#"}, Color: "red"},
+		{Shape: []string{"##"}, Color: "blue", Weight: 3},
+		{Shape: []string{"###"}, Color: "green", Weight: 0},
+	}}
+
+	counts := make(map[string]int)
+	rng := NewRandomizer(1)
+	for i := 0; i < 5000; i++ {
+		counts[set.Generate(rng).Blocks[0].Color]++
+	}
+
+	// Weights 1, 3 and 1 out of 5
+	for color, want := range map[string]float64{"red": 0.2, "blue": 0.6, "green": 0.2} {
+		if got := float64(counts[color]) / 5000; got < want-0.03 || got > want+0.03 {
+			t.Errorf("%s drawn %.2f of the time, want about %.2f", color, got, want)
+		}
+	}
+}
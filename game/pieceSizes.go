@@ -0,0 +1,63 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxPieceSize is the most blocks a piece may have, random or from a piece set
+const MaxPieceSize = 10
+
+// pieceGrowthLevels is how many levels it takes a growing size range to allow
+// pieces one block bigger
+const pieceGrowthLevels = 2
+
+// PieceSizes bounds the random polyominoes, in blocks. Piece sets bring their
+// own shapes and ignore it.
+type PieceSizes struct {
+	Min  int  `json:"min"`
+	Max  int  `json:"max"`
+	Grow bool `json:"grow,omitempty"` // Start just above Min and allow bigger pieces as the level rises
+}
+
+func DefaultPieceSizes() PieceSizes {
+	return PieceSizes{Min: 2, Max: 6}
+}
+
+// ParsePieceSizes reads a range like "2-6", or "4" for a single size
+func ParsePieceSizes(text string) (PieceSizes, error) {
+	low, high, isRange := strings.Cut(text, "-")
+	if !isRange {
+		high = low
+	}
+
+	minSize, err := strconv.Atoi(strings.TrimSpace(low))
+	if err != nil {
+		return PieceSizes{}, fmt.Errorf("piece sizes %q: want a range like 2-6", text)
+	}
+	maxSize, err := strconv.Atoi(strings.TrimSpace(high))
+	if err != nil {
+		return PieceSizes{}, fmt.Errorf("piece sizes %q: want a range like 2-6", text)
+	}
+
+	sizes := PieceSizes{Min: minSize, Max: maxSize}
+	return sizes, sizes.Validate()
+}
+
+func (s PieceSizes) Validate() error {
+	if s.Min < 1 || s.Max > MaxPieceSize || s.Min > s.Max {
+		return fmt.Errorf("piece sizes %d-%d: must be within 1-%d, smallest first", s.Min, s.Max, MaxPieceSize)
+	}
+	return nil
+}
+
+// Range returns the smallest and biggest piece allowed at level. A growing
+// range allows pieces up to Min+1 blocks at level 1 and one block more every
+// pieceGrowthLevels levels, until it reaches Max.
+func (s PieceSizes) Range(level int) (int, int) {
+	if !s.Grow {
+		return s.Min, s.Max
+	}
+	return s.Min, min(s.Min+1+(max(level, 1)-1)/pieceGrowthLevels, s.Max)
+}
@@ -0,0 +1,63 @@
+package game
+
+import "testing"
+
+func TestParsePieceSizes(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    PieceSizes
+		wantErr bool
+	}{
+		{"2-6", PieceSizes{Min: 2, Max: 6}, false},
+		{"4", PieceSizes{Min: 4, Max: 4}, false},
+		{" 3 - 5 ", PieceSizes{Min: 3, Max: 5}, false},
+		{"1-10", PieceSizes{Min: 1, Max: 10}, false},
+		{"0-4", PieceSizes{}, true},
+		{"2-11", PieceSizes{}, true},
+		{"6-2", PieceSizes{}, true},
+		{"2-", PieceSizes{}, true},
+		{"big", PieceSizes{}, true},
+		{"", PieceSizes{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParsePieceSizes(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePieceSizes(%q) error = %v, want error %v", tt.text, err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Errorf("ParsePieceSizes(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPieceSizesRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		sizes   PieceSizes
+		level   int
+		wantMin int
+		wantMax int
+	}{
+		{"fixed", PieceSizes{Min: 2, Max: 6}, 1, 2, 6},
+		{"fixed ignores the level", PieceSizes{Min: 2, Max: 6}, 20, 2, 6},
+		{"growing at level 1", PieceSizes{Min: 2, Max: 6, Grow: true}, 1, 2, 3},
+		{"growing at level 2", PieceSizes{Min: 2, Max: 6, Grow: true}, 2, 2, 3},
+		{"growing at level 3", PieceSizes{Min: 2, Max: 6, Grow: true}, 3, 2, 4},
+		{"growing at level 7", PieceSizes{Min: 2, Max: 6, Grow: true}, 7, 2, 6},
+		{"growing stops at Max", PieceSizes{Min: 2, Max: 6, Grow: true}, 30, 2, 6},
+		{"growing before level 1", PieceSizes{Min: 2, Max: 6, Grow: true}, 0, 2, 3},
+		{"growing a single size", PieceSizes{Min: 4, Max: 4, Grow: true}, 1, 4, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			low, high := tt.sizes.Range(tt.level)
+			if low != tt.wantMin || high != tt.wantMax {
+				t.Errorf("Range(%d) = %d-%d, want %d-%d", tt.level, low, high, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
@@ -1,11 +1,6 @@
 package game
 
-import (
-	"math/rand"
-	"time"
-)
-
-var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+import "sort"
 
 type Player struct {
 	Score           int
@@ -16,9 +11,7 @@ type Player struct {
 	HasSwapped      bool // Track if player has already swapped the current block
 }
 
-func NewPlayer() *Player {
-	nextPolyomino := GeneratePolyomino()
-
+func NewPlayer(nextPolyomino *Polyomino) *Player {
 	return &Player{
 		Score:           0,
 		Level:           1,
@@ -29,9 +22,10 @@ func NewPlayer() *Player {
 	}
 }
 
-func GeneratePolyomino() *Polyomino {
+// GeneratePolyomino grows a random piece of minSize to maxSize blocks
+func GeneratePolyomino(rng *Randomizer, minSize, maxSize int) *Polyomino {
 	blockPositions := []Position{}
-	size := rng.Intn(5) + 1
+	size := minSize - 1 + rng.Intn(maxSize-minSize+1)
 	blockPositions = append(blockPositions, Position{X: 0, Y: 0})
 	for i := 0; i < size; i++ {
 		potentialPositionsSize := len(generateBlockOptions(blockPositions))
@@ -42,17 +36,15 @@ func GeneratePolyomino() *Polyomino {
 		blockPositions = append(blockPositions, generateBlockOptions(blockPositions)[blockPos])
 	}
 
-	colors := []string{
-		"blue",
-		"red",
-		"green",
-		"yellow",
-		"cyan",
-		"magenta",
-		"white",
-	}
-	color := colors[rng.Intn(len(colors))]
+	color := PieceColors[rng.Intn(len(PieceColors))]
+
+	return NewSpawnPolyomino(blockPositions, color)
+}
 
+// NewSpawnPolyomino builds a piece from block offsets, placed just above the
+// middle of the field, or as close to it as a wide piece fits. It rotates
+// around its (0, 0) block.
+func NewSpawnPolyomino(blockPositions []Position, color string) *Polyomino {
 	blocks := make([]Block, len(blockPositions))
 	for i, pos := range blockPositions {
 		blocks[i] = Block{
@@ -61,15 +53,29 @@ func GeneratePolyomino() *Polyomino {
 		}
 	}
 
-	lowestPosition := GetLowestBlockPosition(blocks)
-
 	return &Polyomino{
 		Blocks:   blocks,
-		Position: Position{X: GameFieldWidth / 2, Y: -lowestPosition.Y - 1},
+		Position: SpawnPosition(blocks),
 		Placed:   false,
 	}
 }
 
+// SpawnPosition places blocks just above the middle of the field, or as close
+// to it as a wide piece fits
+func SpawnPosition(blocks []Block) Position {
+	minX, maxX := 0, 0
+	for _, block := range blocks {
+		minX = min(minX, block.Position.X)
+		maxX = max(maxX, block.Position.X)
+	}
+
+	lowestPosition := GetLowestBlockPosition(blocks)
+	return Position{
+		X: min(max(GameFieldWidth/2, -minX), GameFieldWidth-1-maxX),
+		Y: -lowestPosition.Y - 1,
+	}
+}
+
 func GetLowestBlockPosition(blocks []Block) Position {
 	if len(blocks) == 0 {
 		return Position{X: 0, Y: 0}
@@ -110,6 +116,14 @@ func generateBlockOptions(currentPositions []Position) []Position {
 		result = append(result, pos)
 	}
 
+	// Map iteration order is random, sort so the seed alone decides the shape
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Y != result[j].Y {
+			return result[i].Y < result[j].Y
+		}
+		return result[i].X < result[j].X
+	})
+
 	return result
 
 }
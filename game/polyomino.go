@@ -1,10 +1,21 @@
 package game
 
-import "math"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
 
 type Block struct {
 	Position
 	Color string
+
+	// Set when the block locks: the piece it belonged to, numbered in lock
+	// order from 1 (0 for garbage), and that piece's ShapeID
+	PieceID int    `json:",omitempty"`
+	ShapeID string `json:",omitempty"`
 }
 
 type Position struct {
@@ -57,6 +68,84 @@ func CalculateRotationPoint(blocks []Block) Position {
 	}
 }
 
+// ShapeID names a shape independent of its position and rotation, e.g.
+// "0,0 1,0 0,1 1,1" for the O piece. Mirror images are different shapes.
+func ShapeID(blocks []Block) string {
+	cells := make([]Position, len(blocks))
+	for i, block := range blocks {
+		cells[i] = block.Position
+	}
+
+	best := ""
+	for r := 0; r < 4; r++ {
+		if key := shapeKey(cells); best == "" || key < best {
+			best = key
+		}
+		for i, cell := range cells {
+			cells[i] = Position{X: cell.Y, Y: -cell.X}
+		}
+	}
+	return best
+}
+
+// checkShape rejects cells that do not make a playable piece: none at all,
+// more than MaxPieceSize, the same cell twice, cells that do not touch or a
+// piece wider than the field
+func checkShape(cells []Position) error {
+	if len(cells) == 0 {
+		return errors.New("shape has no blocks")
+	}
+	if len(cells) > MaxPieceSize {
+		return fmt.Errorf("shape has %d blocks, at most %d are allowed", len(cells), MaxPieceSize)
+	}
+
+	seen := make(map[Position]bool, len(cells))
+	blocks := make([]Block, len(cells))
+	minX, maxX := cells[0].X, cells[0].X
+	for i, cell := range cells {
+		if seen[cell] {
+			return fmt.Errorf("shape has block %d,%d twice", cell.X, cell.Y)
+		}
+		seen[cell] = true
+		blocks[i] = Block{Position: cell}
+		minX, maxX = min(minX, cell.X), max(maxX, cell.X)
+	}
+
+	if len(blockGroups(blocks, func(a, b Block) bool { return true })) != 1 {
+		return errors.New("shape is not connected")
+	}
+	if width := maxX - minX + 1; width > GameFieldWidth {
+		return fmt.Errorf("shape is %d wide, the field only %d", width, GameFieldWidth)
+	}
+	return nil
+}
+
+// shapeKey lists the cells moved to the origin, row by row
+func shapeKey(cells []Position) string {
+	minX, minY := math.MaxInt, math.MaxInt
+	for _, cell := range cells {
+		minX = min(minX, cell.X)
+		minY = min(minY, cell.Y)
+	}
+
+	normalized := make([]Position, len(cells))
+	for i, cell := range cells {
+		normalized[i] = Position{X: cell.X - minX, Y: cell.Y - minY}
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		if normalized[i].Y != normalized[j].Y {
+			return normalized[i].Y < normalized[j].Y
+		}
+		return normalized[i].X < normalized[j].X
+	})
+
+	parts := make([]string, len(normalized))
+	for i, cell := range normalized {
+		parts[i] = fmt.Sprintf("%d,%d", cell.X, cell.Y)
+	}
+	return strings.Join(parts, " ")
+}
+
 func (t *Polyomino) Move(dx, dy int) {
 	t.Position.X += dx
 	t.Position.Y += dy
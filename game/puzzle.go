@@ -0,0 +1,450 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const ModePuzzle = "puzzle"
+
+// Puzzle goals
+const (
+	GoalClearAll   = "clearAll"   // Empty the board
+	GoalClearLines = "clearLines" // Clear Count lines
+	GoalSurvive    = "survive"    // Lock Count pieces without topping out
+)
+
+// Puzzle is a fixed starting board and piece queue with a goal. Puzzles are
+// JSON files, e.g.
+//
+//	{
+//	  "name": "t-slot",
+//	  "board": ["bbbbbb..bbbbbbb", "bbbbbbb.bbbbbbb"],
+//	  "queue": [{"shape": "T", "color": "magenta"}],
+//	  "goal": {"type": "clearLines", "count": 2}
+//	}
+//
+// Board rows are listed top to bottom and sit on the floor. Every row is
+// GameFieldWidth characters: '.' is empty, '#' is garbage and the first letter
+// of a piece color (b r g y c m w) is a block of that color.
+type Puzzle struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Board       []string      `json:"board"`
+	Queue       []PuzzlePiece `json:"queue"`
+	Goal        PuzzleGoal    `json:"goal"`
+	Gravity     string        `json:"gravity,omitempty"` // Defaults to naive
+}
+
+// PuzzlePiece is a named shape (I O T S Z J L) or blocks written like the
+// console's spawn command, "0,0 1,0 1,1". The piece rotates around 0,0.
+type PuzzlePiece struct {
+	Shape  string `json:"shape,omitempty"`
+	Blocks string `json:"blocks,omitempty"`
+	Color  string `json:"color,omitempty"` // Defaults to white
+}
+
+type PuzzleGoal struct {
+	Type  string `json:"type"`
+	Count int    `json:"count,omitempty"` // Lines for clearLines, pieces for survive
+}
+
+var puzzleColors = map[rune]string{
+	'b': "blue", 'r': "red", 'g': "green", 'y': "yellow",
+	'c': "cyan", 'm': "magenta", 'w': "white", '#': GarbageColor,
+}
+
+// BuiltinPuzzles can be played by name with the puzzle command
+var BuiltinPuzzles = map[string]Puzzle{
+	"first-clear": {
+		Name:        "first-clear",
+		Description: "Fill the gap and leave nothing behind",
+		Board: []string{
+			"ggggggggggggg..",
+			"ggggggggggggg..",
+		},
+		Queue: []PuzzlePiece{{Shape: "O", Color: "yellow"}},
+		Goal:  PuzzleGoal{Type: GoalClearAll},
+	},
+	"t-slot": {
+		Name:        "t-slot",
+		Description: "Stand the T on its side to fill both rows",
+		Board: []string{
+			"bbbbbb..bbbbbbb",
+			"bbbbbbb.bbbbbbb",
+		},
+		Queue: []PuzzlePiece{{Shape: "T", Color: "magenta"}},
+		Goal:  PuzzleGoal{Type: GoalClearLines, Count: 2},
+	},
+	"tetris": {
+		Name:        "tetris",
+		Description: "Four lines with one piece",
+		Board: []string{
+			"rrrrrrrrrrrrrr.",
+			"rrrrrrrrrrrrrr.",
+			"rrrrrrrrrrrrrr.",
+			"rrrrrrrrrrrrrr.",
+		},
+		Queue: []PuzzlePiece{{Shape: "I", Color: "cyan"}},
+		Goal:  PuzzleGoal{Type: GoalClearLines, Count: 4},
+	},
+	"well-and-nook": {
+		Name:        "well-and-nook",
+		Description: "Plug the nook before you use the well",
+		Board: []string{
+			"ccccccccc.ccc..",
+			"ccccccccc.ccc..",
+			"ccccccccc.ccccc",
+		},
+		Queue: []PuzzlePiece{
+			{Shape: "L", Color: "blue"},
+			{Shape: "O", Color: "yellow"},
+			{Shape: "I", Color: "red"},
+			{Shape: "T", Color: "magenta"},
+		},
+		Goal: PuzzleGoal{Type: GoalClearLines, Count: 3},
+	},
+	"chain": {
+		Name:        "chain",
+		Description: "With cascade gravity one clear can start another",
+		Board: []string{
+			"y..............",
+			"y..............",
+			"rrrrrrrrrrrrrr.",
+			".bbbbbbbbbbbbbb",
+		},
+		Queue:   []PuzzlePiece{{Shape: "I", Color: "cyan"}},
+		Goal:    PuzzleGoal{Type: GoalClearLines, Count: 2},
+		Gravity: GravityCascade,
+	},
+	"tall-stack": {
+		Name:        "tall-stack",
+		Description: "Keep a messy stack from reaching the top",
+		Board: []string{
+			"###.###########",
+			"#######.#######",
+			"###########.###",
+			"##.############",
+			"######.########",
+			"##########.####",
+			"#.#############",
+			"#####.#########",
+			"#########.#####",
+			".##############",
+			"####.##########",
+			"########.######",
+			"#############.#",
+			"###.###########",
+			"#######.#######",
+			"###########.###",
+		},
+		Queue: []PuzzlePiece{
+			{Shape: "S", Color: "green"}, {Shape: "Z", Color: "red"}, {Shape: "T", Color: "magenta"},
+			{Shape: "O", Color: "yellow"}, {Shape: "J", Color: "blue"}, {Shape: "L", Color: "white"},
+			{Shape: "Z", Color: "red"}, {Shape: "S", Color: "green"}, {Shape: "I", Color: "cyan"},
+			{Shape: "T", Color: "magenta"}, {Blocks: "0,0 1,0 -1,0 0,1 0,-1", Color: "yellow"},
+			{Shape: "J", Color: "blue"}, {Shape: "S", Color: "green"}, {Shape: "L", Color: "white"},
+			{Shape: "O", Color: "yellow"},
+		},
+		Goal: PuzzleGoal{Type: GoalSurvive, Count: 15},
+	},
+}
+
+// PuzzleNames lists the built-in puzzles in order
+func PuzzleNames() []string {
+	names := make([]string, 0, len(BuiltinPuzzles))
+	for name := range BuiltinPuzzles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadPuzzle returns a built-in puzzle by name or reads a puzzle file
+func LoadPuzzle(nameOrPath string) (Puzzle, error) {
+	if puzzle, ok := BuiltinPuzzles[nameOrPath]; ok {
+		return puzzle, nil
+	}
+
+	data, err := os.ReadFile(nameOrPath)
+	if err != nil {
+		return Puzzle{}, err
+	}
+
+	var puzzle Puzzle
+	if err := json.Unmarshal(data, &puzzle); err != nil {
+		return Puzzle{}, fmt.Errorf("%s: %w", nameOrPath, err)
+	}
+	if err := puzzle.Validate(); err != nil {
+		return Puzzle{}, fmt.Errorf("%s: %w", nameOrPath, err)
+	}
+	return puzzle, nil
+}
+
+func WritePuzzle(path string, puzzle Puzzle) error {
+	data, err := json.MarshalIndent(puzzle, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Validate checks everything a puzzle file can get wrong
+func (p Puzzle) Validate() error {
+	if len(p.Board) > GameFieldHeight {
+		return fmt.Errorf("board has %d rows, the field only %d", len(p.Board), GameFieldHeight)
+	}
+	for i, row := range p.Board {
+		if len([]rune(row)) != GameFieldWidth {
+			return fmt.Errorf("board row %d is %d wide, want %d", i+1, len([]rune(row)), GameFieldWidth)
+		}
+		for _, c := range row {
+			if _, ok := puzzleColors[c]; !ok && c != '.' {
+				return fmt.Errorf("board row %d: unknown cell %q", i+1, c)
+			}
+		}
+	}
+
+	if len(p.Queue) == 0 {
+		return fmt.Errorf("queue is empty")
+	}
+	for i, piece := range p.Queue {
+		if _, err := piece.cells(); err != nil {
+			return fmt.Errorf("queue piece %d: %w", i+1, err)
+		}
+		if piece.Color != "" && !isPieceColor(piece.Color) {
+			return fmt.Errorf("queue piece %d: unknown color %q", i+1, piece.Color)
+		}
+	}
+
+	switch p.Goal.Type {
+	case GoalClearAll:
+	case GoalClearLines:
+		if p.Goal.Count <= 0 {
+			return fmt.Errorf("clearLines needs a count")
+		}
+	case GoalSurvive:
+		if p.Goal.Count <= 0 || p.Goal.Count > len(p.Queue) {
+			return fmt.Errorf("survive needs a count between 1 and the %d queued pieces", len(p.Queue))
+		}
+	default:
+		return fmt.Errorf("unknown goal %q (%s, %s or %s)", p.Goal.Type, GoalClearAll, GoalClearLines, GoalSurvive)
+	}
+
+	if p.Gravity != "" {
+		if _, err := ParseGravity(p.Gravity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isPieceColor(color string) bool {
+	for _, name := range PieceColors {
+		if name == color {
+			return true
+		}
+	}
+	return false
+}
+
+// cells returns the piece's blocks relative to its rotation point
+func (p PuzzlePiece) cells() ([]Position, error) {
+	if p.Shape != "" {
+		shape, ok := ConsoleShapes[strings.ToUpper(p.Shape)]
+		if !ok {
+			return nil, fmt.Errorf("unknown shape %q", p.Shape)
+		}
+		return shape, nil
+	}
+
+	fields := strings.Fields(p.Blocks)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("needs a shape or blocks")
+	}
+
+	cells := make([]Position, 0, len(fields))
+	for _, field := range fields {
+		var cell Position
+		if _, err := fmt.Sscanf(field, "%d,%d", &cell.X, &cell.Y); err != nil {
+			return nil, fmt.Errorf("invalid block %q", field)
+		}
+		cells = append(cells, cell)
+	}
+	if err := checkShape(cells); err != nil {
+		return nil, err
+	}
+	return cells, nil
+}
+
+func (p PuzzlePiece) polyomino() *Polyomino {
+	cells, _ := p.cells()
+
+	color := p.Color
+	if color == "" {
+		color = "white"
+	}
+	return NewSpawnPolyomino(cells, color)
+}
+
+// Blocks returns the starting board. Blocks of one color that touch count
+// as one piece, numbered from -1 down so they never clash with locked pieces.
+func (p Puzzle) Blocks() []Block {
+	blocks := []Block{}
+	top := GameFieldHeight - len(p.Board)
+	for y, row := range p.Board {
+		for x, c := range []rune(row) {
+			if color, ok := puzzleColors[c]; ok {
+				blocks = append(blocks, Block{Position: Position{X: x, Y: top + y}, Color: color})
+			}
+		}
+	}
+
+	groups := blockGroups(blocks, func(a, b Block) bool { return a.Color == b.Color })
+	for i, group := range groups {
+		shape := make([]Block, len(group))
+		for j, index := range group {
+			shape[j] = blocks[index]
+		}
+		shapeID := ShapeID(shape)
+
+		for _, index := range group {
+			blocks[index].PieceID = -(i + 1)
+			blocks[index].ShapeID = shapeID
+		}
+	}
+	return blocks
+}
+
+// Puzzle results
+const (
+	PuzzleSolved = "solved"
+	PuzzleFailed = "failed"
+)
+
+// PuzzleRun is a puzzle being played
+type PuzzleRun struct {
+	Puzzle Puzzle
+	Result string // "" while playing, then PuzzleSolved or PuzzleFailed
+	next   int    // Queue index of the piece after the NEXT one
+}
+
+// NewPuzzleGame plays a puzzle. Nothing about it is random and it never
+// touches the high scores or the autosave.
+func NewPuzzleGame(puzzle Puzzle) *Game {
+	g := NewGame()
+	g.Mode = ModePuzzle
+	g.HighScores = nil
+	g.Puzzle = &PuzzleRun{Puzzle: puzzle}
+	g.Puzzle.start(g)
+	return g
+}
+
+// start sets the board and queue up, also on every restart
+func (r *PuzzleRun) start(g *Game) {
+	r.Result = ""
+	r.next = 0
+
+	g.placedBlocks = r.Puzzle.Blocks()
+	g.Gravity = GravityNaive
+	if r.Puzzle.Gravity != "" {
+		g.Gravity = r.Puzzle.Gravity
+	}
+
+	g.Player.CurrentPolymino = nil
+	g.Player.NextPolyomino = r.take()
+
+	GetLoggerInstance().Info("Puzzle started", "name", r.Puzzle.Name)
+}
+
+// take returns the next piece from the queue, nil once it ran out
+func (r *PuzzleRun) take() *Polyomino {
+	if r.next >= len(r.Puzzle.Queue) {
+		return nil
+	}
+	piece := r.Puzzle.Queue[r.next].polyomino()
+	r.next++
+	return piece
+}
+
+// Remaining is how many pieces are left, counting the one in NEXT
+func (r *PuzzleRun) Remaining(g *Game) int {
+	remaining := len(r.Puzzle.Queue) - r.next
+	if g.Player.NextPolyomino != nil {
+		remaining++
+	}
+	return remaining
+}
+
+// Progress describes how far the goal is, e.g. "LINES 1/4"
+func (r *PuzzleRun) Progress(g *Game) string {
+	switch r.Puzzle.Goal.Type {
+	case GoalClearLines:
+		return fmt.Sprintf("LINES %d/%d", g.Scoring.LinesCleared, r.Puzzle.Goal.Count)
+	case GoalSurvive:
+		return fmt.Sprintf("PIECES %d/%d", g.Stats.PiecesPlaced, r.Puzzle.Goal.Count)
+	default:
+		return fmt.Sprintf("CLEAR %d", len(g.placedBlocks))
+	}
+}
+
+// check decides the puzzle after a piece locked
+func (r *PuzzleRun) check(g *Game) {
+	if r.Result != "" {
+		return
+	}
+
+	solved := false
+	switch r.Puzzle.Goal.Type {
+	case GoalClearAll:
+		solved = len(g.placedBlocks) == 0
+	case GoalClearLines:
+		solved = g.Scoring.LinesCleared >= r.Puzzle.Goal.Count
+	case GoalSurvive:
+		solved = g.Stats.PiecesPlaced >= r.Puzzle.Goal.Count
+	}
+
+	switch {
+	case g.IsGameOver:
+		r.Result = PuzzleFailed
+	case solved:
+		r.Result = PuzzleSolved
+	case g.Player.NextPolyomino == nil:
+		r.Result = PuzzleFailed
+	default:
+		return
+	}
+
+	GetLoggerInstance().Info("Puzzle finished", "name", r.Puzzle.Name, "result", r.Result, "pieces", g.Stats.PiecesPlaced)
+	g.endGame()
+}
+
+// DrawPuzzleSection shows the goal where the personal best normally is
+func (ui *Interface) DrawPuzzleSection(game *Game) {
+	ui.DrawLabel("GOAL", 12, "white")
+	ui.DrawLabel(game.Puzzle.Progress(game), 13, "magenta")
+}
+
+func (ui *Interface) DrawPuzzleResult(run *PuzzleRun) {
+	resultX := ui.view.FieldX + (GameFieldWidth * BlockWidth / 4)
+	resultY := ui.view.FieldY + (GameFieldHeight / 2)
+
+	lines := []string{"FAILED", "", "Press R to retry", "ESC to quit"}
+	color := "red"
+	if run.Result == PuzzleSolved {
+		lines[0] = "SOLVED!"
+		color = "green"
+	}
+
+	for row, line := range lines {
+		if row > 0 {
+			color = "white"
+		}
+		for i, char := range line {
+			ui.renderer.Pixels[resultY+row][resultX+i] = ColoredPixel{Char: char, Color: color}
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package game
+
+import (
+	"strings"
+	"testing"
+)
+
+// puzzleGame sets a puzzle up like NewPuzzleGame, without a terminal
+func puzzleGame(puzzle Puzzle) *Game {
+	g := NewHeadlessGame(1)
+	g.Mode = ModePuzzle
+	g.Puzzle = &PuzzleRun{Puzzle: puzzle}
+	g.Puzzle.start(g)
+	return g
+}
+
+func TestBuiltinPuzzlesAreValid(t *testing.T) {
+	for _, name := range PuzzleNames() {
+		if err := BuiltinPuzzles[name].Validate(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
+
+func TestPuzzleValidate(t *testing.T) {
+	valid := func() Puzzle {
+		return Puzzle{
+			Name:  "valid",
+			Board: []string{"ggggggggggggg.."},
+			Queue: []PuzzlePiece{{Shape: "O"}, {Blocks: "0,0 1,0", Color: "red"}},
+			Goal:  PuzzleGoal{Type: GoalClearAll},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		change  func(p *Puzzle)
+		wantErr string // "" for a valid puzzle
+	}{
+		{"valid", func(p *Puzzle) {}, ""},
+		{"empty board", func(p *Puzzle) { p.Board = nil }, ""},
+		{"survive with a count", func(p *Puzzle) { p.Goal = PuzzleGoal{Type: GoalSurvive, Count: 2} }, ""},
+		{"cascade gravity", func(p *Puzzle) { p.Gravity = GravityCascade }, ""},
+		{"too many rows", func(p *Puzzle) { p.Board = make([]string, GameFieldHeight+1) }, "rows"},
+		{"short row", func(p *Puzzle) { p.Board = []string{"gggg"} }, "is 4 wide"},
+		{"unknown cell", func(p *Puzzle) { p.Board = []string{"gggggggggggggx."} }, "unknown cell"},
+		{"empty queue", func(p *Puzzle) { p.Queue = nil }, "queue is empty"},
+		{"unknown shape", func(p *Puzzle) { p.Queue[0].Shape = "Q" }, "unknown shape"},
+		{"bad blocks", func(p *Puzzle) { p.Queue[1].Blocks = "0,0 x" }, "invalid block"},
+		{"no shape or blocks", func(p *Puzzle) { p.Queue[1] = PuzzlePiece{} }, "needs a shape or blocks"},
+		{"blocks apart", func(p *Puzzle) { p.Queue[1].Blocks = "0,0 2,0" }, "not connected"},
+		{"block twice", func(p *Puzzle) { p.Queue[1].Blocks = "0,0 1,0 0,0" }, "twice"},
+		{"too many blocks", func(p *Puzzle) { p.Queue[1].Blocks = "0,0 1,0 2,0 3,0 4,0 5,0 6,0 7,0 8,0 9,0 10,0" }, "at most"},
+		{"unknown color", func(p *Puzzle) { p.Queue[0].Color = "pink" }, "unknown color"},
+		{"clearLines without count", func(p *Puzzle) { p.Goal = PuzzleGoal{Type: GoalClearLines} }, "needs a count"},
+		{"survive longer than the queue", func(p *Puzzle) { p.Goal = PuzzleGoal{Type: GoalSurvive, Count: 3} }, "between 1 and the 2"},
+		{"unknown goal", func(p *Puzzle) { p.Goal.Type = "win" }, "unknown goal"},
+		{"unknown gravity", func(p *Puzzle) { p.Gravity = "up" }, "unknown gravity"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			puzzle := valid()
+			tt.change(&puzzle)
+
+			err := puzzle.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate() = %v, want no error", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("Validate() passed, want an error about %q", tt.wantErr)
+			case err != nil && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("Validate() = %v, want an error about %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPuzzleBlocksGroupsColors(t *testing.T) {
+	puzzle := Puzzle{Board: []string{"rr.b...........", "r..bb.........#"}}
+
+	pieces := make(map[int]int)
+	for _, block := range puzzle.Blocks() {
+		if block.PieceID >= 0 {
+			t.Errorf("board block at %d,%d has piece %d, want a negative one", block.X, block.Y, block.PieceID)
+		}
+		pieces[block.PieceID]++
+	}
+
+	if len(pieces) != 3 {
+		t.Errorf("board has %d pieces, want the red, the blue and the garbage one", len(pieces))
+	}
+}
+
+func TestPuzzleGoals(t *testing.T) {
+	tests := []struct {
+		name      string
+		goal      PuzzleGoal
+		board     []string // Stack after the lock
+		lines     int
+		pieces    int
+		queueOut  bool // No NEXT piece left
+		toppedOut bool
+		want      string
+	}{
+		{"board cleared", PuzzleGoal{Type: GoalClearAll}, nil, 2, 1, false, false, PuzzleSolved},
+		{"board not cleared yet", PuzzleGoal{Type: GoalClearAll}, []string{"#"}, 1, 1, false, false, ""},
+		{"board not cleared in time", PuzzleGoal{Type: GoalClearAll}, []string{"#"}, 1, 1, true, false, PuzzleFailed},
+		{"enough lines", PuzzleGoal{Type: GoalClearLines, Count: 3}, []string{"#"}, 3, 2, false, false, PuzzleSolved},
+		{"lines with the last piece", PuzzleGoal{Type: GoalClearLines, Count: 3}, []string{"#"}, 4, 2, true, false, PuzzleSolved},
+		{"too few lines", PuzzleGoal{Type: GoalClearLines, Count: 3}, []string{"#"}, 2, 2, false, false, ""},
+		{"out of pieces", PuzzleGoal{Type: GoalClearLines, Count: 3}, []string{"#"}, 2, 2, true, false, PuzzleFailed},
+		{"survived", PuzzleGoal{Type: GoalSurvive, Count: 2}, []string{"#"}, 0, 2, true, false, PuzzleSolved},
+		{"still surviving", PuzzleGoal{Type: GoalSurvive, Count: 2}, []string{"#"}, 0, 1, false, false, ""},
+		{"topped out", PuzzleGoal{Type: GoalSurvive, Count: 2}, []string{"#"}, 0, 2, false, true, PuzzleFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := puzzleGame(Puzzle{
+				Board: []string{"ggggggggggggg.."},
+				Queue: []PuzzlePiece{{Shape: "O"}, {Shape: "O"}},
+				Goal:  tt.goal,
+			})
+
+			g.placedBlocks = pieceBlocks(tt.board...)
+			g.Scoring.LinesCleared = tt.lines
+			g.Stats.PiecesPlaced = tt.pieces
+			if tt.queueOut {
+				g.Player.NextPolyomino = nil
+			}
+			g.IsGameOver = tt.toppedOut
+
+			g.Puzzle.check(g)
+			if g.Puzzle.Result != tt.want {
+				t.Errorf("result %q, want %q", g.Puzzle.Result, tt.want)
+			}
+			if g.IsGameOver != (tt.want != "") {
+				t.Errorf("game over %v with result %q", g.IsGameOver, g.Puzzle.Result)
+			}
+		})
+	}
+}
+
+func TestPuzzlePlaysOut(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"first-clear", PuzzleSolved},
+		{"t-slot", PuzzleSolved},
+		{"tetris", PuzzleSolved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := puzzleGame(BuiltinPuzzles[tt.name])
+			playPieces(g, len(g.Puzzle.Puzzle.Queue))
+
+			if g.Puzzle.Result != tt.want {
+				t.Errorf("bot finished with %q, want %q (%s)", g.Puzzle.Result, tt.want, g.Puzzle.Progress(g))
+			}
+		})
+	}
+}
+
+func TestTSlotNeedsARotation(t *testing.T) {
+	// Drop the T from every column, turned 0 to 3 times
+	solvedWith := make(map[int]bool)
+	for turns := 0; turns < 4; turns++ {
+		for dx := -GameFieldWidth / 2; dx <= GameFieldWidth/2; dx++ {
+			g := puzzleGame(BuiltinPuzzles["t-slot"])
+			g.Advance(SimulationTick) // Spawns the T
+			for i := 0; i < turns; i++ {
+				g.processInput(Event{Action: "up"})
+			}
+			move := "right"
+			if dx < 0 {
+				move = "left"
+			}
+			for i := 0; i < max(dx, -dx); i++ {
+				g.processInput(Event{Action: move})
+			}
+			g.processInput(Event{Action: "hardDrop"})
+
+			if g.Puzzle.Result == PuzzleSolved {
+				solvedWith[turns] = true
+			}
+		}
+	}
+
+	if solvedWith[0] {
+		t.Error("a plain drop solves the puzzle")
+	}
+	if len(solvedWith) == 0 {
+		t.Error("no drop solves the puzzle")
+	}
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"math/rand"
+	"time"
+)
+
+// countingSource wraps the default source and counts how far it has advanced,
+// which together with the seed is enough to restore the generator later.
+type countingSource struct {
+	src   rand.Source64
+	steps uint64
+}
+
+func newCountingSource(seed int64) *countingSource {
+	return &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+}
+
+func (s *countingSource) Int63() int64 {
+	s.steps++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.steps++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.steps = 0
+	s.src.Seed(seed)
+}
+
+// Randomizer is the per-game piece generator. Every game owns one so that
+// games running side by side never share random state.
+type Randomizer struct {
+	*rand.Rand
+	Seed   int64
+	source *countingSource
+}
+
+func NewRandomizer(seed int64) *Randomizer {
+	source := newCountingSource(seed)
+	return &Randomizer{
+		Rand:   rand.New(source),
+		Seed:   seed,
+		source: source,
+	}
+}
+
+// RestoreRandomizer recreates a generator and fast-forwards it to the given step
+func RestoreRandomizer(seed int64, steps uint64) *Randomizer {
+	r := NewRandomizer(seed)
+	for r.source.steps < steps {
+		r.source.Uint64()
+	}
+	return r
+}
+
+// Steps reports how many values have been drawn since the generator was seeded
+func (r *Randomizer) Steps() uint64 {
+	return r.source.steps
+}
+
+func NewSeed() int64 {
+	return time.Now().UnixNano()
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package game
+
+import (
+	"errors"
+	"os"
+)
+
+// makeRaw is not available here, mouse input falls back to the keyboard
+func makeRaw(f *os.File) (restore func(), err error) {
+	return nil, errors.New("mouse input needs a unix terminal")
+}
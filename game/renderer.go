@@ -2,6 +2,8 @@ package game
 
 import (
 	"fmt"
+	"os"
+	"slices"
 	"sync"
 )
 
@@ -15,102 +17,216 @@ type Renderer struct {
 	ScreenHeight int
 	Pixels       [][]ColoredPixel
 	Timer        int
+	layout       Layout   // Fields framed by BuildBorder and their panels
+	view         Viewport // Field the game coordinates currently map to
+
+	termWidth  int // Last known terminal size, 0 when unknown
+	termHeight int
+	resized    chan os.Signal
+	palette    Palette
+	glyphs     GlyphSet
+
+	// What the last Render put on the terminal, see Changed
+	shown     [][]ColoredPixel
+	shownLogs uint64 // Logger.ScreenLines when the log panel was printed
+	logsShown bool
+	stale     bool // The terminal needs a redraw whatever the pixels say
+
+	mouse bool // Mouse reporting, which the reset at the start of Render turns off
 }
 
+// Viewport is the screen position of the top-left cell of a game field
+type Viewport struct {
+	FieldX int
+	FieldY int
+}
+
+var DefaultViewport = Viewport{FieldX: GameFieldStartX, FieldY: GameFieldStartY}
+
+// ScreenUnitWidth is the width of one field and its full side panel,
+// including the border on the left. Split screen places one unit next to the
+// other.
+const ScreenUnitWidth = GameFieldStartX + (GameFieldWidth * BlockWidth) + 19
+
+// logPanelLines is how many log lines Render prints below the screen
+const logPanelLines = 5
+
 var rendererInstance *Renderer
 var rendererOnce sync.Once
 
 func GetRendererInstance() *Renderer {
 	rendererOnce.Do(func() {
-		width := GameFieldStartX + (GameFieldWidth * BlockWidth) + 20
-		height := GameFieldEndY + 3
-
 		rendererInstance = &Renderer{
-			ScreenWidth:  width,
-			ScreenHeight: height,
-			Pixels:       make([][]ColoredPixel, height),
+			resized: make(chan os.Signal, 1),
+			palette: ClassicTheme().Palette(DetectColorDepth()),
+			glyphs:  DetectGlyphSet(),
 		}
+		notifyResize(rendererInstance.resized)
+		rendererInstance.termWidth, rendererInstance.termHeight, _ = terminalSize()
+		rendererInstance.fit(1)
+	})
+	return rendererInstance
+}
 
-		for i := range rendererInstance.Pixels {
-			rendererInstance.Pixels[i] = make([]ColoredPixel, width)
-			for j := range rendererInstance.Pixels[i] {
-				rendererInstance.Pixels[i][j] = ColoredPixel{Char: ' ', Color: ""}
-			}
+// fit picks up terminal resizes (SIGWINCH) and switches to the layout that
+// suits the terminal and the number of fields on screen.
+func (r *Renderer) fit(fields int) {
+	select {
+	case <-r.resized:
+		r.termWidth, r.termHeight, _ = terminalSize()
+		r.stale = true
+	default:
+	}
+
+	// Render ends every row with a newline and may print the log below
+	reserved := 1
+	if GetLoggerInstance().Visible() {
+		reserved += logPanelLines + 1
+	}
+
+	layout := NewLayout(fields, r.termWidth, r.termHeight-reserved)
+	if layout.TooSmall {
+		layout.ScreenWidth, layout.ScreenHeight = max(r.termWidth, 1), max(r.termHeight-1, 1)
+		layout.MinHeight += reserved
+	}
+
+	if r.Pixels == nil || !layout.sameShape(r.layout) {
+		r.layout = layout
+		r.view = layout.Views[0]
+		r.Resize(layout.ScreenWidth, layout.ScreenHeight)
+	}
+}
+
+// SetTheme changes the colors from the next Render on
+func (r *Renderer) SetTheme(theme Theme) {
+	r.palette = theme.Palette(DetectColorDepth())
+	r.stale = true
+}
+
+// SetGlyphs changes the characters the screen is drawn with
+func (r *Renderer) SetGlyphs(glyphs GlyphSet) {
+	r.glyphs = glyphs
+	r.stale = true
+}
+
+// SetMouse keeps the terminal reporting mouse presses across redraws
+func (r *Renderer) SetMouse(on bool) {
+	r.mouse = on
+	r.stale = true
+}
+
+// TooSmall reports whether the terminal cannot show the game right now
+func (r *Renderer) TooSmall() bool {
+	return r.layout.TooSmall
+}
+
+// Resize reallocates the pixel buffer, fit calls it when the layout changes
+func (r *Renderer) Resize(width, height int) {
+	r.ScreenWidth = width
+	r.ScreenHeight = height
+	r.Pixels = make([][]ColoredPixel, height)
+
+	for i := range r.Pixels {
+		r.Pixels[i] = make([]ColoredPixel, width)
+		for j := range r.Pixels[i] {
+			r.Pixels[i][j] = ColoredPixel{Char: ' ', Color: ""}
 		}
+	}
 
-		rendererInstance.BuildBorder()
-	})
-	return rendererInstance
+	r.BuildBorder()
+
+	GetLoggerInstance().Debug("Screen resized", "width", width, "height", height)
 }
 
 func (r *Renderer) BuildBorder() {
-	gameRightWallScreenX := GameFieldStartX + (GameFieldWidth * BlockWidth)
-
-	interfaceWidth := 15
+	// The compact layout has no room for the outer border
+	if !r.layout.Compact || r.layout.TooSmall {
+		r.buildScreenBorder()
+	}
 
-	if gameRightWallScreenX+interfaceWidth >= r.ScreenWidth {
-		GetLoggerInstance().Log("Warning: Screen width may be too small for proper interface display")
+	if r.layout.TooSmall {
+		return
+	}
+	for _, view := range r.layout.Views {
+		r.buildFieldBorder(view)
 	}
+}
 
+func (r *Renderer) buildScreenBorder() {
 	for y := 0; y < r.ScreenHeight; y++ {
 		for x := 0; x < r.ScreenWidth; x++ {
-			// Outer screen border
-			isOuterBorder := y == 0 || y == r.ScreenHeight-1 || x == 0 || x == r.ScreenWidth-1
-
-			// Game field walls (left wall and right wall)
-			isLeftGameWall := x == GameFieldStartX-1 && y > 0 && y < GameFieldEndY
-			isRightGameWall := x == gameRightWallScreenX && y > 0 && y < GameFieldEndY
-
-			// Game field floor (bottom wall)
-			isGameFloor := y == GameFieldEndY && x > GameFieldStartX-1 && x < gameRightWallScreenX+1
-
-			// Game field corners
-			isGameTopLeft := x == GameFieldStartX-1 && y == GameFieldStartY-1
-			isGameTopRight := x == gameRightWallScreenX && y == GameFieldStartY-1
-			isGameBottomLeft := x == GameFieldStartX-1 && y == GameFieldEndY
-			isGameBottomRight := x == gameRightWallScreenX && y == GameFieldEndY
-
-			if isOuterBorder {
-				// Customze the outer border appearance
-				var borderChar rune
-
-				if x == GameFieldStartX-1 && y == 0 {
-					borderChar = '╦' // Connect top border to game's left wall
-				} else if x == gameRightWallScreenX && y == 0 {
-					borderChar = '╦' // Connect top border to game's right wall
-				} else if x == GameFieldStartX-1 && y == r.ScreenHeight-1 {
-					borderChar = '╩' // Connect bottom border to game's left wall
-				} else if x == gameRightWallScreenX && y == r.ScreenHeight-1 {
-					borderChar = '╩' // Connect bottom border to game's right wall
-				} else if x == 0 && y == 0 {
-					borderChar = '╔' // Top-left corner
-				} else if x == r.ScreenWidth-1 && y == 0 {
-					borderChar = '╗' // Top-right corner
-				} else if x == 0 && y == r.ScreenHeight-1 {
-					borderChar = '╚' // Bottom-left corner
-				} else if x == r.ScreenWidth-1 && y == r.ScreenHeight-1 {
-					borderChar = '╝' // Bottom-right corner
-				} else if y == 0 || y == r.ScreenHeight-1 {
-					borderChar = '═' // Horizontal border
-				} else {
-					borderChar = '║' // Vertical border
-				}
+			if y != 0 && y != r.ScreenHeight-1 && x != 0 && x != r.ScreenWidth-1 {
+				continue
+			}
 
-				r.Pixels[y][x] = ColoredPixel{Char: borderChar, Color: "cyan"}
-			} else if isGameTopLeft {
-				r.Pixels[y][x] = ColoredPixel{Char: '╔', Color: "cyan"} // Top-left game field corner
-			} else if isGameTopRight {
-				r.Pixels[y][x] = ColoredPixel{Char: '╗', Color: "cyan"} // Top-right game field corner
-			} else if isGameBottomLeft {
-				r.Pixels[y][x] = ColoredPixel{Char: '╚', Color: "cyan"} // Bottom-left game field corner
-			} else if isGameBottomRight {
-				r.Pixels[y][x] = ColoredPixel{Char: '╝', Color: "cyan"} // Bottom-right game field corner
-			} else if isLeftGameWall || isRightGameWall {
-				r.Pixels[y][x] = ColoredPixel{Char: '║', Color: "cyan"} // Vertical game field walls
-			} else if isGameFloor {
-				r.Pixels[y][x] = ColoredPixel{Char: '═', Color: "cyan"} // Horizontal game field floor
+			var borderChar rune
+
+			if x == 0 && y == 0 {
+				borderChar = '╔' // Top-left corner
+			} else if x == r.ScreenWidth-1 && y == 0 {
+				borderChar = '╗' // Top-right corner
+			} else if x == 0 && y == r.ScreenHeight-1 {
+				borderChar = '╚' // Bottom-left corner
+			} else if x == r.ScreenWidth-1 && y == r.ScreenHeight-1 {
+				borderChar = '╝' // Bottom-right corner
+			} else if y == 0 || y == r.ScreenHeight-1 {
+				borderChar = '═' // Horizontal border
+			} else {
+				borderChar = '║' // Vertical border
 			}
+
+			r.Pixels[y][x] = ColoredPixel{Char: borderChar, Color: ColorBorder}
+		}
+	}
+}
+
+func (r *Renderer) buildFieldBorder(view Viewport) {
+	leftWallX := view.FieldX - 1
+	rightWallX := view.FieldX + (GameFieldWidth * BlockWidth)
+	floorY := view.FieldY + GameFieldHeight
+
+	// A field that does not start at the left edge gets a divider in front of it
+	if dividerX := view.FieldX - GameFieldStartX; dividerX > 0 {
+		for y := 0; y < r.ScreenHeight; y++ {
+			r.Pixels[y][dividerX] = ColoredPixel{Char: '║', Color: ColorBorder}
 		}
+		if !r.layout.Compact {
+			r.Pixels[0][dividerX] = ColoredPixel{Char: '╦', Color: ColorBorder}
+			r.Pixels[r.ScreenHeight-1][dividerX] = ColoredPixel{Char: '╩', Color: ColorBorder}
+		}
+	}
+
+	// Game field walls (left wall and right wall)
+	for y := view.FieldY; y < floorY; y++ {
+		r.Pixels[y][leftWallX] = ColoredPixel{Char: '║', Color: ColorBorder}
+		r.Pixels[y][rightWallX] = ColoredPixel{Char: '║', Color: ColorBorder}
+	}
+
+	// Game field floor (bottom wall)
+	for x := leftWallX + 1; x < rightWallX; x++ {
+		r.Pixels[floorY][x] = ColoredPixel{Char: '═', Color: ColorBorder}
+	}
+
+	// Without a row above the field the walls just run to the top
+	if view.FieldY == 0 {
+		r.Pixels[floorY][leftWallX] = ColoredPixel{Char: '╚', Color: ColorBorder}
+		r.Pixels[floorY][rightWallX] = ColoredPixel{Char: '╝', Color: ColorBorder}
+		return
+	}
+
+	// Connect the walls to the outer border when they touch it
+	topChar, topRightChar := '╔', '╗'
+	if view.FieldY-1 == 0 {
+		topChar, topRightChar = '╦', '╦'
+	}
+	r.Pixels[view.FieldY-1][leftWallX] = ColoredPixel{Char: topChar, Color: ColorBorder}
+	r.Pixels[view.FieldY-1][rightWallX] = ColoredPixel{Char: topRightChar, Color: ColorBorder}
+
+	r.Pixels[floorY][leftWallX] = ColoredPixel{Char: '╚', Color: ColorBorder}
+	r.Pixels[floorY][rightWallX] = ColoredPixel{Char: '╝', Color: ColorBorder}
+	if floorY == r.ScreenHeight-1 {
+		r.Pixels[floorY][leftWallX] = ColoredPixel{Char: '╩', Color: ColorBorder}
+		r.Pixels[floorY][rightWallX] = ColoredPixel{Char: '╩', Color: ColorBorder}
 	}
 }
 
@@ -123,65 +239,74 @@ func (r *Renderer) Clear() {
 }
 
 func (r *Renderer) RenderGame(game *Game) {
+	r.RenderFrame(game)
+}
+
+// RenderFrame draws every game into its own viewport on a fresh buffer
+func (r *Renderer) RenderFrame(games ...*Game) {
+	r.fit(len(games))
+
 	// Clear the entire screen buffer
 	r.Clear()
 
+	if r.layout.TooSmall {
+		r.drawEnlargeMessage()
+		return
+	}
+
 	// First build the border structure
 	r.BuildBorder()
 
-	game.UI.Draw(game)
+	for i, game := range games {
+		game.UI.place(r.layout, i)
+		r.view = game.UI.view
 
-	for _, block := range game.placedBlocks {
-		r.RenderBlock(block, 0, 0)
-	}
+		game.UI.Draw(game)
 
-	if game.Player.CurrentPolymino != nil {
-		r.DrawPolyomino(game.Player.CurrentPolymino)
+		for _, block := range game.placedBlocks {
+			r.RenderBlock(block, 0, 0)
+		}
 
-		GetLoggerInstance().Log(fmt.Sprintf("Current Polyomino - Position: (%d, %d)",
-			game.Player.CurrentPolymino.Position.X,
-			game.Player.CurrentPolymino.Position.Y))
-	}
+		if game.Player.CurrentPolymino != nil {
+			if !game.IsGameOver {
+				r.DrawGhost(game.Player.CurrentPolymino, game.DropDistance())
+			}
+			r.DrawPolyomino(game.Player.CurrentPolymino)
+		}
+
+		game.UI.DrawAnimations(game)
 
-	// For debugging - add the game field boundaries to logs
-	gameRightWallScreenX := GameFieldStartX + (GameFieldWidth * BlockWidth)
-	GetLoggerInstance().Log(fmt.Sprintf("Game boundaries: X=%d-%d, Y=%d-%d, Screen right wall at X=%d",
-		GameFieldStartX, GameFieldEndX, GameFieldStartY, GameFieldEndY, gameRightWallScreenX))
-	GetLoggerInstance().Log(fmt.Sprintf("Screen size: %dx%d", r.ScreenWidth, r.ScreenHeight))
+		// Overlays go last so the stack never hides them
+		game.UI.DrawOverlays(game)
+	}
 }
 
-const (
-	ColorReset   = "\033[0m"
-	ColorRed     = "\033[31m"
-	ColorGreen   = "\033[32m"
-	ColorYellow  = "\033[33m"
-	ColorBlue    = "\033[34m"
-	ColorMagenta = "\033[35m"
-	ColorCyan    = "\033[36m"
-	ColorWhite   = "\033[37m"
-)
+// drawEnlargeMessage replaces the whole screen when nothing fits, a cut off
+// field would only be garbled
+func (r *Renderer) drawEnlargeMessage() {
+	lines := []string{
+		"Please enlarge",
+		"the terminal",
+		fmt.Sprintf("to %dx%d", r.layout.MinWidth, r.layout.MinHeight),
+		fmt.Sprintf("(now %dx%d)", r.termWidth, r.termHeight),
+	}
 
-func GetColorCode(color string) string {
-	switch color {
-	case "red":
-		return ColorRed
-	case "green":
-		return ColorGreen
-	case "yellow":
-		return ColorYellow
-	case "blue":
-		return ColorBlue
-	case "magenta":
-		return ColorMagenta
-	case "cyan":
-		return ColorCyan
-	case "white":
-		return ColorWhite
-	default:
-		return ColorReset
+	startY := max((r.ScreenHeight-len(lines))/2, 0)
+	for row, line := range lines {
+		if startY+row >= r.ScreenHeight {
+			break
+		}
+		startX := max((r.ScreenWidth-len(line))/2, 0)
+		for i, char := range line {
+			if startX+i < r.ScreenWidth {
+				r.Pixels[startY+row][startX+i] = ColoredPixel{Char: char, Color: "yellow"}
+			}
+		}
 	}
 }
 
+const ColorReset = "\033[0m"
+
 func (r *Renderer) RenderBlock(block Block, offsetX, offsetY int) {
 	gameX := offsetX + block.Position.X
 	gameY := offsetY + block.Position.Y
@@ -189,9 +314,9 @@ func (r *Renderer) RenderBlock(block Block, offsetX, offsetY int) {
 	screenX, screenY := r.GameToScreenCoordinates(gameX, gameY)
 
 	if screenX >= 0 && screenX < r.ScreenWidth && screenY >= 0 && screenY < r.ScreenHeight {
-		r.Pixels[screenY][screenX] = ColoredPixel{Char: '█', Color: block.Color}
+		r.Pixels[screenY][screenX] = ColoredPixel{Char: r.glyphs.Block[0], Color: piecePixel + block.Color}
 		if screenX+1 < r.ScreenWidth {
-			r.Pixels[screenY][screenX+1] = ColoredPixel{Char: '█', Color: block.Color}
+			r.Pixels[screenY][screenX+1] = ColoredPixel{Char: r.glyphs.Block[1], Color: piecePixel + block.Color}
 		}
 	}
 }
@@ -210,22 +335,62 @@ func RenderMap(xCoord, yCoord int, pixelMap [][]rune) {
 
 func (r *Renderer) Render() {
 	fmt.Print("\033c")
+	if r.mouse {
+		fmt.Print(mouseOn)
+	}
 
 	for y := 0; y < r.ScreenHeight; y++ {
 		for x := 0; x < r.ScreenWidth; x++ {
 			pixel := r.Pixels[y][x]
+			char := r.glyphs.glyph(pixel.Char)
 
-			if pixel.Color != "" {
-				colorCode := GetColorCode(pixel.Color)
-				fmt.Print(colorCode + string(pixel.Char) + ColorReset)
+			if colorCode := r.palette.Escape(pixel.Color); colorCode != "" {
+				fmt.Print(colorCode + string(char) + ColorReset)
 			} else {
-				fmt.Print(string(pixel.Char))
+				fmt.Print(string(char))
 			}
 		}
 		fmt.Println()
 	}
 
-	GetLoggerInstance().PrintLogs(5)
+	if !r.layout.TooSmall {
+		GetLoggerInstance().PrintLogs(logPanelLines)
+	}
+
+	r.remember()
+}
+
+// Changed reports whether Render would print something other than what is on
+// the terminal already
+func (r *Renderer) Changed() bool {
+	if r.stale || len(r.shown) != len(r.Pixels) {
+		return true
+	}
+
+	logger := GetLoggerInstance()
+	if logger.Visible() != r.logsShown || logger.Visible() && logger.ScreenLines() != r.shownLogs {
+		return true
+	}
+
+	for y := range r.Pixels {
+		if !slices.Equal(r.Pixels[y], r.shown[y]) {
+			return true
+		}
+	}
+	return false
+}
+
+// remember keeps a copy of the rendered frame for Changed
+func (r *Renderer) remember() {
+	if len(r.shown) != len(r.Pixels) {
+		r.shown = make([][]ColoredPixel, len(r.Pixels))
+	}
+	for y := range r.Pixels {
+		r.shown[y] = append(r.shown[y][:0], r.Pixels[y]...)
+	}
+	r.shownLogs = GetLoggerInstance().ScreenLines()
+	r.logsShown = GetLoggerInstance().Visible()
+	r.stale = false
 }
 
 func (r *Renderer) DrawPolyomino(polyomino *Polyomino) {
@@ -236,29 +401,44 @@ func (r *Renderer) DrawPolyomino(polyomino *Polyomino) {
 		screenX, screenY := r.GameToScreenCoordinates(gameX, gameY)
 
 		if screenX >= 0 && screenX+1 < r.ScreenWidth && screenY >= 0 && screenY < r.ScreenHeight {
-			r.Pixels[screenY][screenX] = ColoredPixel{Char: '█', Color: block.Color}
-			r.Pixels[screenY][screenX+1] = ColoredPixel{Char: '█', Color: block.Color}
+			r.Pixels[screenY][screenX] = ColoredPixel{Char: r.glyphs.Block[0], Color: piecePixel + block.Color}
+			r.Pixels[screenY][screenX+1] = ColoredPixel{Char: r.glyphs.Block[1], Color: piecePixel + block.Color}
+		}
+	}
+}
+
+// DrawGhost outlines where the piece would land, distance rows further down
+func (r *Renderer) DrawGhost(polyomino *Polyomino, distance int) {
+	for _, block := range polyomino.Blocks {
+		gameX := polyomino.Position.X + block.Position.X
+		gameY := polyomino.Position.Y + block.Position.Y + distance
+
+		screenX, screenY := r.GameToScreenCoordinates(gameX, gameY)
+
+		if gameY >= 0 && screenX >= 0 && screenX+1 < r.ScreenWidth && screenY >= 0 && screenY < r.ScreenHeight {
+			r.Pixels[screenY][screenX] = ColoredPixel{Char: r.glyphs.Ghost[0], Color: ColorGhost}
+			r.Pixels[screenY][screenX+1] = ColoredPixel{Char: r.glyphs.Ghost[1], Color: ColorGhost}
 		}
 	}
 }
 
 func (r *Renderer) GameToScreenCoordinates(gameX, gameY int) (int, int) {
-	screenX := GameFieldStartX + (gameX * BlockWidth)
-	screenY := GameFieldStartY + gameY
+	screenX := r.view.FieldX + (gameX * BlockWidth)
+	screenY := r.view.FieldY + gameY
 	return screenX, screenY
 }
 
 func (r *Renderer) ScreenToGameCoordinates(screenX, screenY int) (int, int) {
-	gameX := (screenX - GameFieldStartX) / BlockWidth
-	gameY := screenY - GameFieldStartY
+	gameX := (screenX - r.view.FieldX) / BlockWidth
+	gameY := screenY - r.view.FieldY
 	return gameX, gameY
 }
 
 func (r *Renderer) IsInGameArea(screenX, screenY int) bool {
-	gameFieldEndScreenX := GameFieldStartX + (GameFieldWidth * BlockWidth)
+	gameFieldEndScreenX := r.view.FieldX + (GameFieldWidth * BlockWidth)
 
-	return screenX >= GameFieldStartX && screenX < gameFieldEndScreenX &&
-		screenY >= GameFieldStartY && screenY < GameFieldEndY
+	return screenX >= r.view.FieldX && screenX < gameFieldEndScreenX &&
+		screenY >= r.view.FieldY && screenY < r.view.FieldY+GameFieldHeight
 }
 
 func (r *Renderer) IsValidGameCoordinates(gameX, gameY int) bool {
@@ -0,0 +1,243 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	SaveVersion      = 1
+	AutosaveInterval = 15000 // Milliseconds of game time between autosaves
+	saveFile         = "save.json"
+	autosaveFile     = "autosave.json"
+)
+
+// SaveState is everything needed to continue a game exactly where it stopped.
+// Version must be bumped whenever the layout changes incompatibly.
+type SaveState struct {
+	Version      int           `json:"version"`
+	SavedAt      time.Time     `json:"savedAt"`
+	Mode         string        `json:"mode"`
+	Gravity      string        `json:"gravity,omitempty"`
+	PieceSet     *PieceSet     `json:"pieceSet,omitempty"`
+	PieceSizes   *PieceSizes   `json:"pieceSizes,omitempty"`
+	Seed         int64         `json:"seed"`
+	RNGSteps     uint64        `json:"rngSteps"`
+	PlacedBlocks []Block       `json:"placedBlocks"`
+	Current      *Polyomino    `json:"current"`
+	Next         *Polyomino    `json:"next"`
+	HasSwapped   bool          `json:"hasSwapped"`
+	Scoring      ScoringSystem `json:"scoring"`
+	Elapsed      int64         `json:"elapsedMs"`
+	LastDropTime int64         `json:"lastDropTime"`
+	Stats        Statistics    `json:"stats"`
+}
+
+func SavePath() string {
+	return filepath.Join(DataDir(), saveFile)
+}
+
+func AutosavePath() string {
+	return filepath.Join(DataDir(), autosaveFile)
+}
+
+func (g *Game) Snapshot() *SaveState {
+	sizes := g.PieceSizes
+
+	return &SaveState{
+		Version:      SaveVersion,
+		SavedAt:      time.Now().UTC().Truncate(time.Second),
+		Mode:         g.Mode,
+		Gravity:      g.Gravity,
+		PieceSet:     g.PieceSet,
+		PieceSizes:   &sizes,
+		Seed:         g.Seed,
+		RNGSteps:     g.rng.Steps(),
+		PlacedBlocks: g.placedBlocks,
+		Current:      g.Player.CurrentPolymino,
+		Next:         g.Player.NextPolyomino,
+		HasSwapped:   g.Player.HasSwapped,
+		Scoring:      *g.Scoring,
+		Elapsed:      g.timer.elapsed,
+		LastDropTime: g.lastDropTime,
+		Stats:        *g.Stats,
+	}
+}
+
+func (g *Game) Restore(state *SaveState) {
+	g.Reset()
+
+	g.Mode = state.Mode
+	if state.Gravity != "" {
+		g.Gravity = state.Gravity
+	}
+	// A save without a piece set was played with random polyominoes
+	if state.PieceSet == nil || state.PieceSet.Validate() == nil {
+		g.PieceSet = state.PieceSet
+	}
+	// Saves from before piece sizes were configurable used the defaults
+	g.PieceSizes = DefaultPieceSizes()
+	if state.PieceSizes != nil && state.PieceSizes.Validate() == nil {
+		g.PieceSizes = *state.PieceSizes
+	}
+	g.Seed = state.Seed
+	g.rng = RestoreRandomizer(state.Seed, state.RNGSteps)
+
+	g.placedBlocks = state.PlacedBlocks
+	g.Player.CurrentPolymino = state.Current
+	g.Player.NextPolyomino = state.Next
+	g.Player.HasSwapped = state.HasSwapped
+
+	scoring := state.Scoring
+	g.Scoring = &scoring
+
+	g.Stats = restoredStatistics(state.Stats)
+
+	g.timer.Restore(state.Elapsed)
+	g.lastDropTime = state.LastDropTime
+	g.lastAutosave = state.Elapsed
+
+	if g.Player.NextPolyomino == nil {
+		g.Player.NextPolyomino = g.generatePiece()
+	}
+}
+
+func WriteSave(path string, state *SaveState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func ReadSave(path string) (*SaveState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &SaveState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	if state.Version != SaveVersion {
+		return nil, fmt.Errorf("unsupported save version %d (expected %d)", state.Version, SaveVersion)
+	}
+
+	return state, nil
+}
+
+func (g *Game) SaveGame() {
+	if g.IsGameOver {
+		GetLoggerInstance().Warn("Cannot save - game is over")
+		return
+	}
+
+	if g.Puzzle != nil {
+		GetLoggerInstance().Warn("Cannot save a puzzle, R restarts it")
+		return
+	}
+
+	if g.Versus != nil {
+		GetLoggerInstance().Warn("Cannot save a versus match")
+		return
+	}
+
+	if err := WriteSave(SavePath(), g.Snapshot()); err != nil {
+		GetLoggerInstance().Error("Could not save game", "err", err)
+		return
+	}
+	GetLoggerInstance().Info("Game saved")
+}
+
+func (g *Game) LoadGame() {
+	if g.Puzzle != nil {
+		GetLoggerInstance().Warn("Cannot load a saved game during a puzzle")
+		return
+	}
+
+	// The other player would keep playing the old board
+	if g.Versus != nil {
+		GetLoggerInstance().Warn("Cannot load a saved game during a versus match")
+		return
+	}
+
+	state, err := ReadSave(SavePath())
+	if err != nil {
+		GetLoggerInstance().Error("Could not load game", "err", err)
+		return
+	}
+
+	g.Restore(state)
+	GetLoggerInstance().Info("Game loaded")
+}
+
+func (g *Game) autosave() {
+	if g.IsGameOver || !g.persistent() {
+		return
+	}
+
+	g.lastAutosave = g.timer.elapsed
+	if err := WriteSave(AutosavePath(), g.Snapshot()); err != nil {
+		GetLoggerInstance().Error("Autosave failed", "err", err)
+	}
+}
+
+func (g *Game) autosaveIfDue() {
+	if g.timer.elapsed-g.lastAutosave >= AutosaveInterval {
+		g.autosave()
+	}
+}
+
+// discardAutosave removes the autosave once a run has ended, so only
+// unfinished games are offered for resuming.
+func (g *Game) discardAutosave() {
+	err := os.Remove(AutosavePath())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		GetLoggerInstance().Warn("Could not remove autosave", "err", err)
+	}
+}
+
+// checkForResume looks for an autosave left behind by a quit or a crash and,
+// if one exists, pauses the game behind a resume prompt.
+func (g *Game) checkForResume() {
+	if !g.persistent() {
+		return
+	}
+
+	state, err := ReadSave(AutosavePath())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			GetLoggerInstance().Warn("Ignoring autosave", "err", err)
+		}
+		return
+	}
+
+	g.pendingResume = state
+}
+
+func (g *Game) handleResumePrompt(event Event) {
+	switch event.Char {
+	case 'y', 'Y':
+		g.Restore(g.pendingResume)
+		g.pendingResume = nil
+		GetLoggerInstance().Info("Resumed autosaved game")
+	case 'n', 'N':
+		g.pendingResume = nil
+		g.discardAutosave()
+		g.timer.Reset()
+	}
+}
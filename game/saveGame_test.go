@@ -0,0 +1,129 @@
+package game
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// playPieces lets the bot place n more pieces, or fewer if it tops out
+func playPieces(g *Game, n int) {
+	if g.Bot == nil {
+		g.Bot = NewBot(DefaultBotWeights())
+	}
+
+	target := g.Stats.PiecesPlaced + n
+	for !g.IsGameOver && g.Stats.PiecesPlaced < target {
+		if action := g.Bot.NextAction(g); action != "" {
+			g.processInput(Event{Action: action})
+		}
+		g.Advance(SimulationTick)
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+	tetrominoes := BuiltinPieceSets["tetrominoes"]
+
+	tests := []struct {
+		name  string
+		setup func(g *Game)
+	}{
+		{"new game", func(g *Game) {}},
+		{"after some pieces", func(g *Game) { playPieces(g, 30) }},
+		{"sticky gravity", func(g *Game) {
+			g.Gravity = GravitySticky
+			playPieces(g, 30)
+		}},
+		{"piece set", func(g *Game) {
+			g.SetPieceSet(&tetrominoes)
+			playPieces(g, 30)
+		}},
+		{"growing piece sizes", func(g *Game) {
+			g.PieceSizes = PieceSizes{Min: 1, Max: 8, Grow: true}
+			g.ResetWithSeed(g.Seed)
+			playPieces(g, 30)
+		}},
+		{"after a swap", func(g *Game) {
+			playPieces(g, 5)
+			g.SwapBlocks()
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewHeadlessGame(11)
+			tt.setup(g)
+
+			path := filepath.Join(t.TempDir(), "save.json")
+			if err := WriteSave(path, g.Snapshot()); err != nil {
+				t.Fatal(err)
+			}
+			state, err := ReadSave(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			restored := NewHeadlessGame(99)
+			restored.Restore(state)
+
+			want, got := g.Snapshot(), restored.Snapshot()
+			got.SavedAt = want.SavedAt
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("restored game differs:\ngot  %+v\nwant %+v", got, want)
+			}
+
+			// The random generator picks up where it stopped, so both games
+			// keep getting the same pieces
+			playPieces(g, 20)
+			playPieces(restored, 20)
+			if !reflect.DeepEqual(restored.placedBlocks, g.placedBlocks) || restored.Scoring.Score != g.Scoring.Score {
+				t.Errorf("games went apart after the restore: score %d and %d", restored.Scoring.Score, g.Scoring.Score)
+			}
+		})
+	}
+}
+
+func TestReadSaveChecksVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"current version", `{"version": 1, "mode": "marathon"}`, false},
+		{"no version", `{"mode": "marathon"}`, true},
+		{"newer version", `{"version": 2, "mode": "marathon"}`, true},
+		{"not json", `version 1`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "save.json")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := ReadSave(path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadSave error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRestoreFallsBackOnBadSettings(t *testing.T) {
+	g := NewHeadlessGame(3)
+	state := g.Snapshot()
+	state.PieceSizes = &PieceSizes{Min: 5, Max: 2}
+	state.PieceSet = &PieceSet{Name: "broken"}
+
+	restored := NewHeadlessGame(4)
+	restored.Restore(state)
+
+	if restored.PieceSizes != DefaultPieceSizes() {
+		t.Errorf("piece sizes %+v, want the defaults", restored.PieceSizes)
+	}
+	if restored.PieceSet != nil {
+		t.Errorf("kept the invalid piece set %+v", restored.PieceSet)
+	}
+}
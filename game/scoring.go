@@ -24,6 +24,12 @@ func (s *ScoringSystem) AddScore(points int) {
 }
 
 func (s *ScoringSystem) AddLines(lines int) {
+	s.AddChainLines(lines, 1)
+}
+
+// AddChainLines scores the chain-th clear in a row caused by one lock, a link
+// of a chain is worth chain times the usual points
+func (s *ScoringSystem) AddChainLines(lines, chain int) {
 	if lines <= 0 {
 		return
 	}
@@ -32,11 +38,11 @@ func (s *ScoringSystem) AddLines(lines int) {
 
 	s.Level = (s.LinesCleared / 10) + 1
 
+	points := PointsPerLine * lines
 	if lines == 4 {
-		s.AddScore(PointsPerLineTetris)
-	} else {
-		s.AddScore(PointsPerLine * lines)
+		points = PointsPerLineTetris
 	}
+	s.AddScore(points * max(chain, 1))
 }
 
 func (s *ScoringSystem) GetDropSpeed() int64 {
@@ -0,0 +1,222 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ScriptInput presses keys from a script instead of the keyboard, for
+// reproducible end-to-end runs and machines without a keyboard device.
+// Scripts have one step per line, e.g.
+//
+//	# Comments and blank lines are skipped
+//	500ms left     wait 500ms, then press left
+//	hardDrop       press hardDrop right away
+//	wait 2s        only wait
+//	type ANNA      type text, e.g. a high score name
+//	quit           end the run like Esc does, so does the end of the script
+//
+// Actions are the ones the default keys are bound to. Waits count steps of
+// the game loop rather than wall time, so with a fixed seed a script plays
+// out the same on every run.
+type ScriptInput struct {
+	steps   []scriptStep
+	next    int
+	elapsed time.Duration // Game loop time since the script started
+	due     time.Duration // When steps[next] is due
+	events  chan Event
+	quit    chan bool
+	ended   bool
+}
+
+type scriptStep struct {
+	line   int
+	delay  time.Duration
+	events []Event
+	quit   bool
+}
+
+// steppedInput is an InputSource that keeps time with the game loop, the
+// game steps it before handling the input of every step
+type steppedInput interface {
+	InputSource
+	Step(d time.Duration)
+}
+
+// LoadScript reads a script from a file, "-" reads it from stdin
+func LoadScript(path string) (*ScriptInput, error) {
+	if path == "-" {
+		return ParseScript(os.Stdin)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	script, err := ParseScript(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return script, nil
+}
+
+func ParseScript(r io.Reader) (*ScriptInput, error) {
+	actions := scriptActions()
+
+	var steps []scriptStep
+	total := 0
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		step := scriptStep{line: line}
+		fields := strings.Fields(text)
+		if delay, err := time.ParseDuration(fields[0]); err == nil {
+			if delay < 0 {
+				return nil, fmt.Errorf("line %d: negative wait %s", line, fields[0])
+			}
+			step.delay = delay
+			fields = fields[1:]
+		}
+
+		if len(fields) > 0 {
+			action := fields[0]
+			switch {
+			case action == "wait":
+				delay, err := parseWait(fields[1:])
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				step.delay += delay
+			case action == "type":
+				typed := strings.TrimSpace(strings.TrimPrefix(strings.Join(fields, " "), "type"))
+				if typed == "" {
+					return nil, fmt.Errorf("line %d: nothing to type", line)
+				}
+				for _, char := range typed {
+					step.events = append(step.events, typedEvent(char))
+				}
+			case action == "quit" && len(fields) == 1:
+				step.quit = true
+			case actions[action] && len(fields) == 1:
+				step.events = append(step.events, Event{Action: action})
+			default:
+				return nil, fmt.Errorf("line %d: unknown step %q", line, text)
+			}
+		}
+
+		total += len(step.events)
+		steps = append(steps, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	script := &ScriptInput{
+		steps:  steps,
+		events: make(chan Event, total),
+		quit:   make(chan bool),
+	}
+	if len(steps) > 0 {
+		script.due = steps[0].delay
+	}
+	return script, nil
+}
+
+func parseWait(fields []string) (time.Duration, error) {
+	if len(fields) != 1 {
+		return 0, fmt.Errorf("want wait <duration>, e.g. wait 2s")
+	}
+
+	delay, err := time.ParseDuration(fields[0])
+	if err != nil || delay < 0 {
+		return 0, fmt.Errorf("bad wait %q", fields[0])
+	}
+	return delay, nil
+}
+
+// scriptActions are the actions the default keys are bound to
+func scriptActions() map[string]bool {
+	keymap := DefaultKeymap()
+
+	actions := make(map[string]bool)
+	for _, binding := range keymap.Keys {
+		actions[binding.Action] = true
+	}
+	for _, binding := range keymap.Chars {
+		actions[binding.Action] = true
+	}
+	return actions
+}
+
+// typedEvent is the event the keyboard would send for char
+func typedEvent(char rune) Event {
+	if char == ' ' {
+		return Event{Action: "space"}
+	}
+
+	event, _ := DefaultKeymap().Translate(char, 0)
+	return event
+}
+
+func (s *ScriptInput) Start() error {
+	return nil
+}
+
+// Step moves the script d further and queues every key press that is due
+func (s *ScriptInput) Step(d time.Duration) {
+	if s.ended {
+		return
+	}
+	s.elapsed += d
+
+	for s.next < len(s.steps) && s.due <= s.elapsed {
+		step := s.steps[s.next]
+		for _, event := range step.events {
+			logger.Debug("Script input", "line", step.line, "action", event.Action)
+			s.events <- event
+		}
+		if step.quit {
+			s.end()
+			return
+		}
+
+		s.next++
+		if s.next < len(s.steps) {
+			s.due += s.steps[s.next].delay
+		}
+	}
+
+	if s.next == len(s.steps) {
+		s.end()
+	}
+}
+
+func (s *ScriptInput) end() {
+	if !s.ended {
+		s.ended = true
+		close(s.quit)
+	}
+}
+
+func (s *ScriptInput) Events() <-chan Event {
+	return s.events
+}
+
+func (s *ScriptInput) QuitChannel() <-chan bool {
+	return s.quit
+}
+
+func (s *ScriptInput) Stop() {
+	s.end()
+}
@@ -0,0 +1,307 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SimulationTick is how far a headless bot game advances between two bot
+// actions. The live bot acts once every botActionInterval of the fixed steps,
+// so both play at the same pace.
+const SimulationTick = botActionInterval
+
+type SimulationConfig struct {
+	Games      int
+	Workers    int
+	Seed       int64 // Game i is played with Seed+i
+	MaxPieces  int   // Stop a game after this many pieces, 0 means no limit
+	Weights    BotWeights
+	PieceSet   *PieceSet  // Random polyominoes when nil
+	PieceSizes PieceSizes // The zero value keeps the default sizes
+}
+
+type SimulationResult struct {
+	Seed      int64
+	Score     int
+	Lines     int
+	Pieces    int
+	Survival  int64 // Milliseconds of game time until top out or the piece limit
+	ToppedOut bool
+}
+
+// PlayBotGame plays one headless game with the bot from start to finish,
+// cfg.Games, cfg.Workers and cfg.Seed are not used
+func PlayBotGame(seed int64, cfg SimulationConfig) SimulationResult {
+	g := NewHeadlessGame(seed)
+	g.Bot = NewBot(cfg.Weights)
+	if cfg.PieceSizes != (PieceSizes{}) {
+		g.PieceSizes = cfg.PieceSizes
+	}
+	g.SetPieceSet(cfg.PieceSet)
+
+	for !g.IsGameOver && (cfg.MaxPieces == 0 || g.Stats.PiecesPlaced < cfg.MaxPieces) {
+		if action := g.Bot.NextAction(g); action != "" {
+			g.processInput(Event{Action: action})
+		}
+		g.Advance(SimulationTick)
+	}
+
+	return SimulationResult{
+		Seed:      seed,
+		Score:     g.Scoring.Score,
+		Lines:     g.Scoring.LinesCleared,
+		Pieces:    g.Stats.PiecesPlaced,
+		Survival:  g.timer.elapsed,
+		ToppedOut: g.IsGameOver,
+	}
+}
+
+// Simulate plays cfg.Games bot games spread over cfg.Workers goroutines. The
+// results are ordered by seed, independent of scheduling.
+func Simulate(cfg SimulationConfig) []SimulationResult {
+	workers := max(cfg.Workers, 1)
+	results := make([]SimulationResult, cfg.Games)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = PlayBotGame(cfg.Seed+int64(i), cfg)
+			}
+		}()
+	}
+
+	for i := 0; i < cfg.Games; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+type Distribution struct {
+	Min    float64
+	P10    float64
+	Median float64
+	Mean   float64
+	P90    float64
+	Max    float64
+	StdDev float64
+}
+
+func Summarize(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / float64(len(sorted))
+
+	var variance float64
+	for _, v := range sorted {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(sorted))
+
+	percentile := func(p float64) float64 {
+		return sorted[int(math.Round(p*float64(len(sorted)-1)))]
+	}
+
+	return Distribution{
+		Min:    sorted[0],
+		P10:    percentile(0.1),
+		Median: percentile(0.5),
+		Mean:   mean,
+		P90:    percentile(0.9),
+		Max:    sorted[len(sorted)-1],
+		StdDev: math.Sqrt(variance),
+	}
+}
+
+func FormatSimulationReport(results []SimulationResult) string {
+	scores := make([]float64, len(results))
+	lines := make([]float64, len(results))
+	survival := make([]float64, len(results))
+	toppedOut := 0
+	for i, r := range results {
+		scores[i] = float64(r.Score)
+		lines[i] = float64(r.Lines)
+		survival[i] = float64(r.Survival) / 1000
+		if r.ToppedOut {
+			toppedOut++
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d games, %d topped out\n", len(results), toppedOut)
+	fmt.Fprintf(&b, "%-10s %10s %10s %10s %10s %10s %10s %10s\n", "", "min", "p10", "median", "mean", "p90", "max", "stddev")
+	for _, row := range []struct {
+		name   string
+		values []float64
+	}{
+		{"score", scores},
+		{"lines", lines},
+		{"survival s", survival},
+	} {
+		d := Summarize(row.values)
+		fmt.Fprintf(&b, "%-10s %10.0f %10.0f %10.0f %10.1f %10.0f %10.0f %10.1f\n",
+			row.name, d.Min, d.P10, d.Median, d.Mean, d.P90, d.Max, d.StdDev)
+	}
+
+	return b.String()
+}
+
+type TuneConfig struct {
+	Generations int
+	Population  int
+	Elite       int // Best candidates the next distribution is fitted to
+	Games       int // Games per candidate, shared by the whole generation
+	MaxPieces   int
+	Workers     int
+	Seed        int64
+	PieceSet    *PieceSet  // Pieces the candidates play with, random polyominoes when nil
+	PieceSizes  PieceSizes // Zero for the default sizes
+}
+
+// TuneWeights searches for bot weights with the cross-entropy method: sample a
+// population from a normal distribution per weight, score every candidate by
+// mean lines cleared and refit the distribution to the elite. The progress
+// callback is invoked after every generation.
+func TuneWeights(cfg TuneConfig, progress func(generation int, best BotWeights, fitness float64)) BotWeights {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	elite := min(max(cfg.Elite, 1), cfg.Population)
+
+	mean := weightsToVector(DefaultBotWeights())
+	stddev := make([]float64, len(mean))
+	for i := range stddev {
+		stddev[i] = 0.5
+	}
+
+	best := DefaultBotWeights()
+	bestFitness := math.Inf(-1)
+
+	for generation := 0; generation < cfg.Generations; generation++ {
+		type candidate struct {
+			vector  []float64
+			fitness float64
+		}
+		candidates := make([]candidate, cfg.Population)
+		for i := range candidates {
+			vector := make([]float64, len(mean))
+			for j := range vector {
+				vector[j] = mean[j] + rng.NormFloat64()*stddev[j]
+			}
+			candidates[i].vector = vector
+		}
+
+		// Every candidate in a generation plays the same seeds so they are
+		// compared on equal terms
+		seed := cfg.Seed + int64(generation)*int64(cfg.Games)
+		for i := range candidates {
+			results := Simulate(SimulationConfig{
+				Games:      cfg.Games,
+				Workers:    cfg.Workers,
+				Seed:       seed,
+				MaxPieces:  cfg.MaxPieces,
+				Weights:    vectorToWeights(candidates[i].vector),
+				PieceSet:   cfg.PieceSet,
+				PieceSizes: cfg.PieceSizes,
+			})
+
+			total := 0
+			for _, r := range results {
+				total += r.Lines
+			}
+			candidates[i].fitness = float64(total) / float64(len(results))
+		}
+
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].fitness > candidates[j].fitness
+		})
+
+		if candidates[0].fitness > bestFitness {
+			bestFitness = candidates[0].fitness
+			best = vectorToWeights(candidates[0].vector)
+		}
+
+		for j := range mean {
+			var sum float64
+			for _, c := range candidates[:elite] {
+				sum += c.vector[j]
+			}
+			mean[j] = sum / float64(elite)
+
+			var variance float64
+			for _, c := range candidates[:elite] {
+				variance += (c.vector[j] - mean[j]) * (c.vector[j] - mean[j])
+			}
+			// A little extra noise keeps the search from collapsing too early
+			stddev[j] = math.Sqrt(variance/float64(elite)) + 0.05
+		}
+
+		if progress != nil {
+			progress(generation+1, best, bestFitness)
+		}
+	}
+
+	return best
+}
+
+func weightsToVector(w BotWeights) []float64 {
+	return []float64{w.AggregateHeight, w.Holes, w.Bumpiness, w.LinesCleared, w.Wells}
+}
+
+func vectorToWeights(v []float64) BotWeights {
+	return BotWeights{
+		AggregateHeight: v[0],
+		Holes:           v[1],
+		Bumpiness:       v[2],
+		LinesCleared:    v[3],
+		Wells:           v[4],
+	}
+}
+
+func SaveBotWeights(path string, weights BotWeights) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(weights, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func LoadBotWeights(path string) (BotWeights, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return BotWeights{}, err
+	}
+
+	weights := DefaultBotWeights()
+	if err := json.Unmarshal(data, &weights); err != nil {
+		return BotWeights{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return weights, nil
+}
@@ -0,0 +1,76 @@
+package game
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Distribution
+	}{
+		{
+			name: "no values",
+			want: Distribution{},
+		},
+		{
+			name:   "one value",
+			values: []float64{5},
+			want:   Distribution{Min: 5, P10: 5, Median: 5, Mean: 5, P90: 5, Max: 5},
+		},
+		{
+			name:   "unsorted",
+			values: []float64{3, 1, 2},
+			want:   Distribution{Min: 1, P10: 1, Median: 2, Mean: 2, P90: 3, Max: 3, StdDev: math.Sqrt(2.0 / 3)},
+		},
+		{
+			name:   "one to ten",
+			values: []float64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1},
+			want:   Distribution{Min: 1, P10: 2, Median: 6, Mean: 5.5, P90: 9, Max: 10, StdDev: math.Sqrt(8.25)},
+		},
+		{
+			name:   "all the same",
+			values: []float64{4, 4, 4, 4},
+			want:   Distribution{Min: 4, P10: 4, Median: 4, Mean: 4, P90: 4, Max: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := append([]float64(nil), tt.values...)
+			got := Summarize(values)
+
+			if math.Abs(got.StdDev-tt.want.StdDev) > 1e-9 {
+				t.Errorf("StdDev = %v, want %v", got.StdDev, tt.want.StdDev)
+			}
+			got.StdDev = tt.want.StdDev
+			if got != tt.want {
+				t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+			}
+			if !reflect.DeepEqual(values, tt.values) {
+				t.Errorf("Summarize reordered its input to %v", values)
+			}
+		})
+	}
+}
+
+func TestSimulateIndependentOfWorkers(t *testing.T) {
+	cfg := SimulationConfig{Games: 6, Seed: 5, MaxPieces: 40, Weights: DefaultBotWeights()}
+
+	cfg.Workers = 1
+	serial := Simulate(cfg)
+	cfg.Workers = 4
+	parallel := Simulate(cfg)
+
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Errorf("results depend on the workers:\n%+v\n%+v", serial, parallel)
+	}
+	for i, result := range serial {
+		if result.Seed != cfg.Seed+int64(i) {
+			t.Errorf("result %d has seed %d, want %d", i, result.Seed, cfg.Seed+int64(i))
+		}
+	}
+}
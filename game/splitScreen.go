@@ -0,0 +1,170 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+// SplitScreen runs two games side by side on one terminal, each with its own
+// state, keys and side panel.
+type SplitScreen struct {
+	Games        []*Game
+	eventHandler *EventHandler
+	garbageRng   *Randomizer
+}
+
+// splitGarbageTarget hands garbage straight to the other board's queue
+type splitGarbageTarget struct {
+	split *SplitScreen
+	to    *Game
+}
+
+func (t splitGarbageTarget) SendGarbage(lines int) {
+	t.to.Garbage.Push(lines, t.split.garbageRng.Intn(GameFieldWidth))
+}
+
+func NewSplitScreen(garbage bool) *SplitScreen {
+	renderer := GetRendererInstance()
+
+	eventHandler := NewEventHandler()
+	eventHandler.Keymap = SplitScreenKeymap()
+
+	seed := NewSeed()
+	split := &SplitScreen{
+		eventHandler: eventHandler,
+		garbageRng:   NewRandomizer(seed),
+	}
+
+	// Both players get the same pieces. The renderer lays the boards out side
+	// by side when they are drawn.
+	for i := 0; i < 2; i++ {
+		g := NewHeadlessGame(seed)
+		g.Mode = ModeSplit
+		g.UI = NewInterface(renderer)
+		g.UI.Animations.Subscribe(g.Events)
+		g.UI.Title = fmt.Sprintf("PLAYER %d", i+1)
+		split.Games = append(split.Games, g)
+	}
+
+	if garbage {
+		split.Games[0].GarbageTarget = splitGarbageTarget{split: split, to: split.Games[1]}
+		split.Games[1].GarbageTarget = splitGarbageTarget{split: split, to: split.Games[0]}
+	}
+
+	return split
+}
+
+func (s *SplitScreen) Start() error {
+	renderer := GetRendererInstance()
+	if err := s.eventHandler.Start(); err != nil {
+		return err
+	}
+	for _, g := range s.Games {
+		g.timer.Reset()
+	}
+
+	defer s.eventHandler.Stop()
+
+	ticker := time.NewTicker(stepInterval)
+	defer ticker.Stop()
+
+	clock := newStepClock()
+	var frames frameLimiter
+
+	running := true
+	for running {
+		select {
+		case <-s.eventHandler.QuitChannel():
+			running = false
+		case <-ticker.C:
+			for steps := clock.due(); steps > 0; steps-- {
+				s.handleEvents()
+				for _, g := range s.Games {
+					g.step()
+				}
+			}
+
+			renderer.RenderFrame(s.Games...)
+
+			// Nobody can play a board they cannot see
+			if renderer.TooSmall() && !s.paused() {
+				s.togglePause()
+			}
+
+			frames.present(renderer)
+		}
+	}
+	return nil
+}
+
+// handleEvents handles every key press that arrived since the last step
+func (s *SplitScreen) handleEvents() {
+	for {
+		select {
+		case event := <-s.eventHandler.InputEvents:
+			s.handleEvent(event)
+		default:
+			return
+		}
+	}
+}
+
+func (s *SplitScreen) handleEvent(event Event) {
+	switch event.Action {
+	case "restart":
+		s.restartIfFinished()
+		return
+	case "pause":
+		s.togglePause()
+		return
+	case "char":
+		// Keys neither player has
+		return
+	}
+
+	if event.Player < 0 || event.Player >= len(s.Games) {
+		return
+	}
+
+	g := s.Games[event.Player]
+	if !g.IsGameOver {
+		g.processInput(event)
+	}
+}
+
+// togglePause pauses or resumes both boards together, so neither player
+// gets to play on alone
+func (s *SplitScreen) togglePause() {
+	paused := s.paused()
+	for _, g := range s.Games {
+		if paused {
+			g.Resume()
+		} else {
+			g.Pause()
+		}
+	}
+}
+
+func (s *SplitScreen) paused() bool {
+	for _, g := range s.Games {
+		if g.Paused {
+			return true
+		}
+	}
+	return false
+}
+
+// restartIfFinished starts a new round once every board has topped out
+func (s *SplitScreen) restartIfFinished() {
+	for _, g := range s.Games {
+		if !g.IsGameOver {
+			return
+		}
+	}
+
+	seed := NewSeed()
+	s.garbageRng = NewRandomizer(seed)
+	for _, g := range s.Games {
+		g.ResetWithSeed(seed)
+	}
+}
@@ -0,0 +1,125 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxClearLines is the most rows one piece can fill at once, as tall as the
+// biggest piece
+const MaxClearLines = MaxPieceSize
+
+type Statistics struct {
+	PiecesPlaced   int
+	Inputs         int
+	Clears         map[int]int // Number of clears keyed by lines cleared at once
+	PieceSizes     map[int]int // Number of placed pieces keyed by block count
+	MaxStackHeight int
+}
+
+func NewStatistics() *Statistics {
+	return &Statistics{
+		Clears:     make(map[int]int),
+		PieceSizes: make(map[int]int),
+	}
+}
+
+// restoredStatistics copies decoded statistics, filling in maps that were
+// empty when they were written.
+func restoredStatistics(s Statistics) *Statistics {
+	if s.Clears == nil {
+		s.Clears = make(map[int]int)
+	}
+	if s.PieceSizes == nil {
+		s.PieceSizes = make(map[int]int)
+	}
+	return &s
+}
+
+func (s *Statistics) RecordInput() {
+	s.Inputs++
+}
+
+func (s *Statistics) RecordPiece(polyomino *Polyomino) {
+	s.PiecesPlaced++
+	s.PieceSizes[len(polyomino.Blocks)]++
+}
+
+func (s *Statistics) RecordClear(lines int) {
+	if lines > 0 {
+		s.Clears[min(lines, MaxClearLines)]++
+	}
+}
+
+func (s *Statistics) RecordStackHeight(blocks []Block) {
+	height := StackHeight(blocks)
+	if height > s.MaxStackHeight {
+		s.MaxStackHeight = height
+	}
+}
+
+// PiecesPerSecond is calculated from the game timer elapsed milliseconds
+func (s *Statistics) PiecesPerSecond(elapsed int64) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(s.PiecesPlaced) / (float64(elapsed) / 1000)
+}
+
+func (s *Statistics) InputsPerPiece() float64 {
+	if s.PiecesPlaced == 0 {
+		return 0
+	}
+	return float64(s.Inputs) / float64(s.PiecesPlaced)
+}
+
+// StackHeight returns the number of rows between the floor and the highest
+// placed block.
+func StackHeight(blocks []Block) int {
+	height := 0
+	for _, block := range blocks {
+		if GameFieldHeight-block.Position.Y > height {
+			height = GameFieldHeight - block.Position.Y
+		}
+	}
+	return height
+}
+
+// Lines formats the statistics as short rows that fit in the side panel.
+func (s *Statistics) Lines(elapsed int64) []string {
+	lines := []string{
+		fmt.Sprintf("PIECES %d", s.PiecesPlaced),
+		fmt.Sprintf("PPS %.2f", s.PiecesPerSecond(elapsed)),
+		fmt.Sprintf("KPP %.2f", s.InputsPerPiece()),
+		fmt.Sprintf("MAX H %d", s.MaxStackHeight),
+		fmt.Sprintf("CLR1-3 %d/%d/%d", s.Clears[1], s.Clears[2], s.Clears[3]),
+		fmt.Sprintf("CLR4-6 %d/%d/%d", s.Clears[4], s.Clears[5], s.Clears[6]),
+	}
+
+	// Only pieces of 7 blocks and more can clear that many
+	bigClears := 0
+	for lines := 7; lines <= MaxClearLines; lines++ {
+		bigClears += s.Clears[lines]
+	}
+	if bigClears > 0 {
+		lines = append(lines, fmt.Sprintf("CLR7+ %d", bigClears))
+	}
+
+	sizes := make([]int, 0, len(s.PieceSizes))
+	for size := range s.PieceSizes {
+		sizes = append(sizes, size)
+	}
+	sort.Ints(sizes)
+
+	row := "SIZE"
+	for i, size := range sizes {
+		if i > 0 && i%3 == 0 {
+			lines = append(lines, row)
+			row = "    "
+		}
+		row += fmt.Sprintf(" %d:%d", size, s.PieceSizes[size])
+	}
+	lines = append(lines, row)
+
+	return lines
+}
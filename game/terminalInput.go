@@ -0,0 +1,175 @@
+package game
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/eiannone/keyboard"
+)
+
+// The keyboard package takes any unknown escape sequence for Esc, so with the
+// mouse on the terminal is read here instead. mouseOn asks the terminal to
+// report button presses and the wheel as SGR sequences, mouseOff stops it.
+const (
+	mouseOn  = "\033[?1000h\033[?1006h"
+	mouseOff = "\033[?1000l\033[?1006l"
+)
+
+// SGR mouse buttons, with the motion and modifier bits masked out
+const (
+	mouseLeft      = 0
+	mouseRight     = 2
+	mouseWheelUp   = 64
+	mouseWheelDown = 65
+)
+
+// escapeKeys are the sequences of the special keys the keymaps bind
+var escapeKeys = map[string]keyboard.Key{
+	"\033[A":   keyboard.KeyArrowUp,
+	"\033[B":   keyboard.KeyArrowDown,
+	"\033[C":   keyboard.KeyArrowRight,
+	"\033[D":   keyboard.KeyArrowLeft,
+	"\033OA":   keyboard.KeyArrowUp,
+	"\033OB":   keyboard.KeyArrowDown,
+	"\033OC":   keyboard.KeyArrowRight,
+	"\033OD":   keyboard.KeyArrowLeft,
+	"\033OQ":   keyboard.KeyF2,
+	"\033[12~": keyboard.KeyF2,
+	"\033[15~": keyboard.KeyF5,
+	"\033[20~": keyboard.KeyF9,
+}
+
+// EnableMouse lets the player click a column of the field to move the piece
+// there, scroll to rotate it and right-click to drop it
+func (g *Game) EnableMouse() {
+	if handler, ok := g.Input.(*EventHandler); ok {
+		handler.Mouse = true
+	}
+}
+
+// startTerminal reads keys and mouse presses straight from the terminal
+func (e *EventHandler) startTerminal() error {
+	restore, err := makeRaw(os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	renderer := GetRendererInstance()
+	renderer.SetMouse(true)
+	e.restore = func() {
+		renderer.SetMouse(false)
+		fmt.Print(mouseOff)
+		restore()
+	}
+
+	go e.readTerminal(os.Stdin)
+	return nil
+}
+
+// readTerminal sends an event for every key press and mouse press read from
+// in, until Esc is pressed
+func (e *EventHandler) readTerminal(in io.Reader) {
+	buf := make([]byte, 256)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			logger.Error("Error reading terminal", "err", err)
+			return
+		}
+
+		for input := buf[:n]; len(input) > 0; {
+			// A lone escape byte is the Esc key
+			if len(input) == 1 && input[0] == '\033' {
+				e.Quit <- true
+				return
+			}
+
+			event, size, ok := e.parseTerminalInput(input)
+			input = input[size:]
+			if ok {
+				logger.Debug("Input", "action", event.Action)
+				e.InputEvents <- event
+			}
+		}
+	}
+}
+
+// parseTerminalInput translates the first key or mouse press in input and
+// returns how many bytes it took. Unknown sequences are skipped.
+func (e *EventHandler) parseTerminalInput(input []byte) (Event, int, bool) {
+	if input[0] != '\033' {
+		// Control keys have the keyboard package's key codes
+		if key := keyboard.Key(input[0]); key <= keyboard.KeySpace || key == keyboard.KeyBackspace2 {
+			event, ok := e.Keymap.Translate(0, key)
+			return event, 1, ok
+		}
+
+		char, size := utf8.DecodeRune(input)
+		if char == utf8.RuneError {
+			return Event{}, size, false
+		}
+		event, ok := e.Keymap.Translate(char, 0)
+		return event, size, ok
+	}
+
+	if bytes.HasPrefix(input, []byte("\033[<")) {
+		end := bytes.IndexAny(input, "Mm")
+		if end < 0 {
+			return Event{}, len(input), false
+		}
+		event, ok := parseMouse(string(input[3:end]), input[end] == 'M')
+		return event, end + 1, ok
+	}
+
+	for sequence, key := range escapeKeys {
+		if bytes.HasPrefix(input, []byte(sequence)) {
+			event, ok := e.Keymap.Translate(0, key)
+			return event, len(sequence), ok
+		}
+	}
+
+	// Skip to the next sequence
+	size := 1
+	for size < len(input) && input[size] != '\033' {
+		size++
+	}
+	return Event{}, size, false
+}
+
+// parseMouse turns the "button;column;row" of an SGR mouse report into an
+// event: a left click moves the piece to the column, the wheel rotates it and
+// a right click drops it. Releases are ignored.
+func parseMouse(report string, pressed bool) (Event, bool) {
+	fields := strings.Split(report, ";")
+	if len(fields) != 3 || !pressed {
+		return Event{}, false
+	}
+
+	var values [3]int
+	for i, field := range fields {
+		value, err := strconv.Atoi(field)
+		if err != nil {
+			return Event{}, false
+		}
+		values[i] = value
+	}
+
+	// Shift, meta, control and motion bits
+	button := values[0] &^ (4 | 8 | 16 | 32)
+	x, y := values[1]-1, values[2]-1
+
+	switch button {
+	case mouseLeft:
+		return Event{Action: "moveTo", X: x, Y: y}, true
+	case mouseWheelUp, mouseWheelDown:
+		return Event{Action: "up"}, true
+	case mouseRight:
+		return Event{Action: "hardDrop"}, true
+	}
+	return Event{}, false
+}
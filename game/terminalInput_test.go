@@ -0,0 +1,78 @@
+package game
+
+import "testing"
+
+func TestParseMouse(t *testing.T) {
+	tests := []struct {
+		name    string
+		report  string
+		pressed bool
+		want    Event
+		wantOk  bool
+	}{
+		{"left click", "0;5;3", true, Event{Action: "moveTo", X: 4, Y: 2}, true},
+		{"left click in the corner", "0;1;1", true, Event{Action: "moveTo"}, true},
+		{"left click with shift", "4;10;7", true, Event{Action: "moveTo", X: 9, Y: 6}, true},
+		{"left drag", "32;10;7", true, Event{Action: "moveTo", X: 9, Y: 6}, true},
+		{"right click", "2;5;3", true, Event{Action: "hardDrop"}, true},
+		{"right click with control", "18;5;3", true, Event{Action: "hardDrop"}, true},
+		{"wheel up", "64;5;3", true, Event{Action: "up"}, true},
+		{"wheel down", "65;5;3", true, Event{Action: "up"}, true},
+		{"wheel with meta", "72;5;3", true, Event{Action: "up"}, true},
+		{"middle click", "1;5;3", true, Event{}, false},
+		{"release", "0;5;3", false, Event{}, false},
+		{"missing row", "0;5", true, Event{}, false},
+		{"extra field", "0;5;3;1", true, Event{}, false},
+		{"not a number", "0;x;3", true, Event{}, false},
+		{"empty", "", true, Event{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseMouse(tt.report, tt.pressed)
+			if ok != tt.wantOk || got != tt.want {
+				t.Errorf("parseMouse(%q, %v) = %+v, %v, want %+v, %v", tt.report, tt.pressed, got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestParseTerminalInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		want     Event
+		wantSize int
+		wantOk   bool
+	}{
+		{"arrow", "\033[A", Event{Action: "up"}, 3, true},
+		{"arrow in application mode", "\033OD", Event{Action: "left"}, 3, true},
+		{"function key", "\033[15~", Event{Action: "save"}, 5, true},
+		{"only the first key", "\033[Bd", Event{Action: "down"}, 3, true},
+		{"click", "\033[<0;5;3M", Event{Action: "moveTo", X: 4, Y: 2}, 9, true},
+		{"release", "\033[<0;5;3m", Event{}, 9, false},
+		{"cut off mouse report", "\033[<0;5", Event{}, 6, false},
+		{"unknown sequence", "\033[Z\033[A", Event{}, 3, false},
+		{"space", " ", Event{Action: "space"}, 1, true},
+		{"enter", "\r", Event{Action: "enter"}, 1, true},
+		{"tab", "\t", Event{Action: "stats"}, 1, true},
+		{"backspace", "\x7f", Event{Action: "backspace"}, 1, true},
+		{"unbound control key", "\x01", Event{}, 1, false},
+		{"bound char", "d", Event{Action: "hardDrop", Char: 'd'}, 1, true},
+		{"bound char in upper case", "C", Event{Action: "swap", Char: 'C'}, 1, true},
+		{"unbound char", "x", Event{Action: "char", Char: 'x'}, 1, true},
+		{"multibyte char", "é", Event{Action: "char", Char: 'é'}, 2, true},
+		{"invalid utf-8", "\xffd", Event{}, 1, false},
+	}
+
+	handler := &EventHandler{Keymap: DefaultKeymap()}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, size, ok := handler.parseTerminalInput([]byte(tt.input))
+			if ok != tt.wantOk || size != tt.wantSize || got != tt.want {
+				t.Errorf("parseTerminalInput(%q) = %+v, %d, %v, want %+v, %d, %v",
+					tt.input, got, size, ok, tt.want, tt.wantSize, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package game
+
+import "os"
+
+// terminalSize is unknown here, the renderer falls back to the full layout
+func terminalSize() (width, height int, ok bool) {
+	return 0, 0, false
+}
+
+func notifyResize(signals chan<- os.Signal) {}
@@ -0,0 +1,24 @@
+//go:build unix
+
+package game
+
+import (
+	"os"
+	"os/signal"
+
+	"golang.org/x/sys/unix"
+)
+
+// terminalSize reports the columns and rows of the terminal on stdout, ok is
+// false when stdout is not a terminal.
+func terminalSize() (width, height int, ok bool) {
+	size, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ)
+	if err != nil || size.Col == 0 || size.Row == 0 {
+		return 0, 0, false
+	}
+	return int(size.Col), int(size.Row), true
+}
+
+func notifyResize(signals chan<- os.Signal) {
+	signal.Notify(signals, unix.SIGWINCH)
+}
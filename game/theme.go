@@ -0,0 +1,359 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	ColorBorder = "border"
+	ColorGhost  = "ghost"
+
+	// piecePixel prefixes the color of pixels that belong to a piece, so a
+	// theme can color a red piece differently from red text
+	piecePixel = "piece:"
+
+	themeFile = "theme.json"
+)
+
+// PieceColors are the color slots pieces are generated with. Themes decide
+// what each slot looks like, the slots themselves never change so a seed
+// always produces the same game.
+var PieceColors = []string{"blue", "red", "green", "yellow", "cyan", "magenta", "white"}
+
+// Theme maps the game's color names to terminal colors. A color is a basic
+// name ("red", "bright-red"), a 256-color index ("208"), 24-bit hex
+// ("#ff8800") or "default" for the terminal's own color. Missing or empty
+// entries fall back to the classic theme.
+type Theme struct {
+	Name   string            `json:"name"`
+	Pieces map[string]string `json:"pieces"` // Keyed by PieceColors
+	Text   map[string]string `json:"text"`   // Keyed by the color names the panel and overlays use
+	Border string            `json:"border"`
+	Ghost  string            `json:"ghost"`
+	Glyphs string            `json:"glyphs"` // unicode, single, ascii or auto (the default)
+}
+
+func ClassicTheme() Theme {
+	names := func() map[string]string {
+		colors := map[string]string{}
+		for _, name := range PieceColors {
+			colors[name] = name
+		}
+		return colors
+	}
+
+	return Theme{
+		Name:   "classic",
+		Pieces: names(),
+		Text:   names(),
+		Border: "cyan",
+		Ghost:  "bright-black",
+	}
+}
+
+// BuiltinThemes can be picked by name with --theme
+var BuiltinThemes = map[string]Theme{
+	"classic": ClassicTheme(),
+	"vivid": {
+		Name: "vivid",
+		Pieces: map[string]string{
+			"blue":    "#3b82f6",
+			"red":     "#ef4444",
+			"green":   "#22c55e",
+			"yellow":  "#eab308",
+			"cyan":    "#06b6d4",
+			"magenta": "#d946ef",
+			"white":   "#e5e7eb",
+		},
+		Border: "#64748b",
+		Ghost:  "#475569",
+	},
+	"mono": {
+		Name:   "mono",
+		Pieces: allColors("default"),
+		Text:   allColors("default"),
+		Border: "default",
+		Ghost:  "default",
+	},
+}
+
+func allColors(color string) map[string]string {
+	colors := map[string]string{}
+	for _, name := range PieceColors {
+		colors[name] = color
+	}
+	return colors
+}
+
+func ThemePath() string {
+	return filepath.Join(DataDir(), themeFile)
+}
+
+// LoadTheme returns a built-in theme by name or reads a theme file. An empty
+// name uses theme.json in the data directory if there is one.
+func LoadTheme(nameOrPath string) (Theme, error) {
+	if nameOrPath == "" {
+		theme, err := readTheme(ThemePath())
+		if errors.Is(err, os.ErrNotExist) {
+			return ClassicTheme(), nil
+		}
+		return theme, err
+	}
+
+	if theme, ok := BuiltinThemes[nameOrPath]; ok {
+		return theme, nil
+	}
+	return readTheme(nameOrPath)
+}
+
+func readTheme(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+
+	var theme Theme
+	if err := json.Unmarshal(data, &theme); err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	// Validate up front, a typo should not silently turn into no color
+	colors := []string{theme.Border, theme.Ghost}
+	for _, color := range theme.Pieces {
+		colors = append(colors, color)
+	}
+	for _, color := range theme.Text {
+		colors = append(colors, color)
+	}
+	for _, color := range colors {
+		if _, err := parseColor(color); err != nil {
+			return Theme{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if _, err := GlyphSetByName(theme.Glyphs); err != nil {
+		return Theme{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return theme, nil
+}
+
+// ColorDepth is how many colors the terminal can show
+type ColorDepth int
+
+const (
+	ColorDepthNone ColorDepth = iota // NO_COLOR, nothing but the terminal default
+	ColorDepth16
+	ColorDepth256
+	ColorDepthTrue
+)
+
+// DetectColorDepth follows the NO_COLOR, COLORTERM and TERM conventions
+func DetectColorDepth() ColorDepth {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorDepthNone
+	}
+
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return ColorDepthTrue
+	}
+
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return ColorDepth256
+	}
+	return ColorDepth16
+}
+
+// Palette holds the escape sequence for every color name a pixel can have
+type Palette map[string]string
+
+// Palette resolves the theme for a terminal with the given depth
+func (t Theme) Palette(depth ColorDepth) Palette {
+	t = t.withDefaults()
+
+	palette := Palette{
+		ColorBorder: colorEscape(t.Border, depth),
+		ColorGhost:  colorEscape(t.Ghost, depth),
+	}
+	for name, color := range t.Pieces {
+		palette[piecePixel+name] = colorEscape(color, depth)
+	}
+	for name, color := range t.Text {
+		palette[name] = colorEscape(color, depth)
+	}
+	return palette
+}
+
+// withDefaults fills everything the theme leaves out from the classic theme
+func (t Theme) withDefaults() Theme {
+	classic := ClassicTheme()
+
+	merge := func(colors, fallback map[string]string) map[string]string {
+		merged := map[string]string{}
+		for name, color := range fallback {
+			merged[name] = color
+		}
+		for name, color := range colors {
+			if color != "" {
+				merged[name] = color
+			}
+		}
+		return merged
+	}
+
+	merged := Theme{
+		Name:   t.Name,
+		Pieces: merge(t.Pieces, classic.Pieces),
+		Text:   merge(t.Text, classic.Text),
+		Border: t.Border,
+		Ghost:  t.Ghost,
+	}
+	if merged.Border == "" {
+		merged.Border = classic.Border
+	}
+	if merged.Ghost == "" {
+		merged.Ghost = classic.Ghost
+	}
+	return merged
+}
+
+// Escape returns the escape sequence for a pixel color, "" for the default
+func (p Palette) Escape(color string) string {
+	return p[color]
+}
+
+// rgbColor is a parsed theme color, basic colors are kept as ANSI codes
+type rgbColor struct {
+	basic   int // 30-37 or 90-97, 0 when not a basic color
+	index   int // 256-color index, -1 when not set
+	r, g, b int
+	defined bool // False for the terminal default
+}
+
+var basicColors = map[string]int{
+	"black": 30, "red": 31, "green": 32, "yellow": 33,
+	"blue": 34, "magenta": 35, "cyan": 36, "white": 37,
+}
+
+func parseColor(spec string) (rgbColor, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" || spec == "default" {
+		return rgbColor{index: -1}, nil
+	}
+
+	if code, ok := basicColors[strings.TrimPrefix(spec, "bright-")]; ok {
+		if strings.HasPrefix(spec, "bright-") {
+			code += 60
+		}
+		return rgbColor{basic: code, index: -1, defined: true}, nil
+	}
+
+	if hex, ok := strings.CutPrefix(spec, "#"); ok {
+		value, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil || len(hex) != 6 {
+			return rgbColor{}, fmt.Errorf("invalid color %q", spec)
+		}
+		return rgbColor{
+			index:   -1,
+			r:       int(value >> 16 & 0xff),
+			g:       int(value >> 8 & 0xff),
+			b:       int(value & 0xff),
+			defined: true,
+		}, nil
+	}
+
+	index, err := strconv.Atoi(spec)
+	if err != nil || index < 0 || index > 255 {
+		return rgbColor{}, fmt.Errorf("invalid color %q", spec)
+	}
+	r, g, b := indexToRGB(index)
+	return rgbColor{index: index, r: r, g: g, b: b, defined: true}, nil
+}
+
+// colorEscape turns a theme color into the best escape the terminal supports
+func colorEscape(spec string, depth ColorDepth) string {
+	color, err := parseColor(spec)
+	if err != nil || !color.defined || depth == ColorDepthNone {
+		return ""
+	}
+
+	switch {
+	case color.basic != 0:
+		return fmt.Sprintf("\033[%dm", color.basic)
+	case depth == ColorDepthTrue && color.index < 0:
+		return fmt.Sprintf("\033[38;2;%d;%d;%dm", color.r, color.g, color.b)
+	case depth >= ColorDepth256:
+		index := color.index
+		if index < 0 {
+			index = rgbToIndex(color.r, color.g, color.b)
+		}
+		return fmt.Sprintf("\033[38;5;%dm", index)
+	default:
+		return fmt.Sprintf("\033[%dm", nearestBasic(color.r, color.g, color.b))
+	}
+}
+
+// cubeLevels are the channel values of the 6x6x6 part of the 256 palette
+var cubeLevels = []int{0, 95, 135, 175, 215, 255}
+
+// basicRGB approximates the 16 basic colors, indexed like the 256 palette
+var basicRGB = [16][3]int{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+func indexToRGB(index int) (int, int, int) {
+	switch {
+	case index < 16:
+		c := basicRGB[index]
+		return c[0], c[1], c[2]
+	case index < 232:
+		index -= 16
+		return cubeLevels[index/36], cubeLevels[index/6%6], cubeLevels[index%6]
+	default:
+		gray := 8 + (index-232)*10
+		return gray, gray, gray
+	}
+}
+
+func rgbToIndex(r, g, b int) int {
+	level := func(v int) int {
+		best := 0
+		for i, l := range cubeLevels {
+			if abs(v-l) < abs(v-cubeLevels[best]) {
+				best = i
+			}
+		}
+		return best
+	}
+	return 16 + 36*level(r) + 6*level(g) + level(b)
+}
+
+func nearestBasic(r, g, b int) int {
+	best, bestDistance := 0, -1
+	for i, c := range basicRGB {
+		distance := (r-c[0])*(r-c[0]) + (g-c[1])*(g-c[1]) + (b-c[2])*(b-c[2])
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	if best < 8 {
+		return 30 + best
+	}
+	return 90 + best - 8
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,353 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// VersusProtocolVersion is sent in every message. Peers with a different
+// version are rejected during the handshake.
+const VersusProtocolVersion = 1
+
+// versusCloseTimeout is how long Close waits for queued messages to go out
+const versusCloseTimeout = time.Second
+
+const (
+	MessageHello    = "hello"
+	MessageState    = "state"
+	MessageGarbage  = "garbage"
+	MessageGameOver = "gameover"
+	MessageBye      = "bye"
+)
+
+const (
+	VersusWin  = "win"
+	VersusLose = "lose"
+)
+
+// VersusMessage is one line of the versus protocol, encoded as JSON
+type VersusMessage struct {
+	Version int     `json:"v"`
+	Type    string  `json:"type"`
+	Seed    int64   `json:"seed,omitempty"`
+	Lines   int     `json:"lines,omitempty"`
+	Hole    int     `json:"hole"`
+	Score   int     `json:"score,omitempty"`
+	Cleared int     `json:"cleared,omitempty"`
+	Level   int     `json:"level,omitempty"`
+	Board   []Block `json:"board,omitempty"`
+}
+
+type OpponentState struct {
+	Score   int
+	Lines   int
+	Level   int
+	Height  int
+	Board   []Block
+	Left    bool // Connection closed before the game ended
+	Updated bool // At least one state message has arrived
+}
+
+type VersusSession struct {
+	Seed     int64
+	Opponent OpponentState
+	Result   string // VersusWin or VersusLose once the game is decided
+
+	conn       net.Conn
+	encoder    *json.Encoder
+	incoming   chan VersusMessage
+	outgoing   chan VersusMessage // Written by writeLoop, off the game loop
+	written    chan struct{}      // Closed once writeLoop is done
+	closeOnce  sync.Once
+	garbageRng *Randomizer
+}
+
+// HostVersus waits for one opponent on addr and hands it the shared seed
+func HostVersus(addr string, seed int64) (*VersusSession, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+
+	conn, err := listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return HostVersusConn(conn, seed)
+}
+
+// JoinVersus connects to a hosting instance and takes over its seed
+func JoinVersus(addr string) (*VersusSession, error) {
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return JoinVersusConn(conn)
+}
+
+// HostVersusConn runs the hosting side of the handshake on an open
+// connection, so the protocol can be driven over loopback or net.Pipe.
+func HostVersusConn(conn net.Conn, seed int64) (*VersusSession, error) {
+	session := newVersusSession(conn, seed)
+	if err := session.write(VersusMessage{Type: MessageHello, Seed: seed}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	reader := bufio.NewReader(conn)
+	if _, err := session.expectHello(reader); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	session.start(reader)
+	return session, nil
+}
+
+// JoinVersusConn runs the joining side of the handshake on an open connection
+func JoinVersusConn(conn net.Conn) (*VersusSession, error) {
+	reader := bufio.NewReader(conn)
+
+	session := newVersusSession(conn, 0)
+	hello, err := session.expectHello(reader)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	session.Seed = hello.Seed
+	session.garbageRng = NewRandomizer(hello.Seed)
+
+	if err := session.write(VersusMessage{Type: MessageHello, Seed: hello.Seed}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	session.start(reader)
+	return session, nil
+}
+
+func newVersusSession(conn net.Conn, seed int64) *VersusSession {
+	return &VersusSession{
+		Seed:       seed,
+		conn:       conn,
+		encoder:    json.NewEncoder(conn),
+		incoming:   make(chan VersusMessage, 64),
+		outgoing:   make(chan VersusMessage, 64),
+		written:    make(chan struct{}),
+		garbageRng: NewRandomizer(seed),
+	}
+}
+
+// start reads and writes messages in the background once the handshake is done
+func (s *VersusSession) start(reader *bufio.Reader) {
+	go s.readLoop(reader)
+	go s.writeLoop()
+}
+
+func (s *VersusSession) expectHello(reader *bufio.Reader) (VersusMessage, error) {
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return VersusMessage{}, err
+	}
+
+	var msg VersusMessage
+	if err := json.Unmarshal(line, &msg); err != nil {
+		return VersusMessage{}, err
+	}
+	if msg.Type != MessageHello {
+		return VersusMessage{}, fmt.Errorf("expected hello, got %q", msg.Type)
+	}
+	if msg.Version != VersusProtocolVersion {
+		return VersusMessage{}, fmt.Errorf("opponent speaks protocol version %d, we speak %d", msg.Version, VersusProtocolVersion)
+	}
+
+	return msg, nil
+}
+
+func (s *VersusSession) readLoop(reader *bufio.Reader) {
+	defer close(s.incoming)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				GetLoggerInstance().Warn("Versus connection closed", "err", err)
+			}
+			return
+		}
+
+		var msg VersusMessage
+		if err := json.Unmarshal(line, &msg); err != nil {
+			GetLoggerInstance().Warn("Ignoring bad versus message", "err", err)
+			continue
+		}
+		s.incoming <- msg
+	}
+}
+
+// writeLoop writes the queued messages until Close. After a failed write the
+// rest are dropped, the read side notices the closed connection.
+func (s *VersusSession) writeLoop() {
+	defer close(s.written)
+
+	failed := false
+	for msg := range s.outgoing {
+		if failed {
+			continue
+		}
+		if err := s.write(msg); err != nil {
+			GetLoggerInstance().Warn("Could not send versus message", "type", msg.Type, "err", err)
+			failed = true
+		}
+	}
+}
+
+func (s *VersusSession) write(msg VersusMessage) error {
+	msg.Version = VersusProtocolVersion
+	return s.encoder.Encode(msg)
+}
+
+// send queues msg for writeLoop, so a slow connection does not hold up the
+// game loop. A state that does not fit is dropped, the next lock sends a
+// newer one.
+func (s *VersusSession) send(msg VersusMessage) {
+	if msg.Type != MessageState {
+		s.outgoing <- msg
+		return
+	}
+
+	select {
+	case s.outgoing <- msg:
+	default:
+		GetLoggerInstance().Debug("Dropping versus state, the connection is behind")
+	}
+}
+
+// Close says bye and closes the connection once the queued messages are
+// written, or after versusCloseTimeout if the connection is stuck
+func (s *VersusSession) Close() error {
+	s.closeOnce.Do(func() {
+		select {
+		case s.outgoing <- VersusMessage{Type: MessageBye}:
+		default:
+		}
+		close(s.outgoing)
+	})
+
+	select {
+	case <-s.written:
+	case <-time.After(versusCloseTimeout):
+	}
+	return s.conn.Close()
+}
+
+// Update applies everything the opponent sent since the last call. It is
+// called from the game loop so all game changes stay on one goroutine.
+func (s *VersusSession) Update(g *Game) {
+	for {
+		select {
+		case msg, ok := <-s.incoming:
+			if !ok {
+				s.opponentLeft(g)
+				return
+			}
+			s.handle(g, msg)
+		default:
+			return
+		}
+	}
+}
+
+func (s *VersusSession) handle(g *Game, msg VersusMessage) {
+	switch msg.Type {
+	case MessageState:
+		s.Opponent.Score = msg.Score
+		s.Opponent.Lines = msg.Cleared
+		s.Opponent.Level = msg.Level
+		s.Opponent.Board = msg.Board
+		s.Opponent.Height = StackHeight(msg.Board)
+		s.Opponent.Updated = true
+	case MessageGarbage:
+		// The peer is not trusted, a whole field of garbage is the most
+		// one message can do
+		if msg.Hole < 0 || msg.Hole >= GameFieldWidth {
+			GetLoggerInstance().Warn("Ignoring garbage with the hole outside the field", "hole", msg.Hole)
+			return
+		}
+		g.Garbage.Push(min(max(msg.Lines, 0), GameFieldHeight), msg.Hole)
+	case MessageGameOver:
+		if s.Result == "" {
+			s.Result = VersusWin
+			g.endGame()
+		}
+	case MessageBye:
+		s.opponentLeft(g)
+	}
+}
+
+func (s *VersusSession) opponentLeft(g *Game) {
+	if s.Opponent.Left {
+		return
+	}
+
+	s.Opponent.Left = true
+	if s.Result == "" {
+		s.Result = VersusWin
+		g.endGame()
+	}
+}
+
+// SendGarbage picks the hole from the shared garbage generator and sends the
+// rows to the opponent.
+func (s *VersusSession) SendGarbage(lines int) {
+	s.send(VersusMessage{Type: MessageGarbage, Lines: lines, Hole: s.garbageRng.Intn(GameFieldWidth)})
+}
+
+func (s *VersusSession) sendState(g *Game) {
+	s.send(VersusMessage{
+		Type:    MessageState,
+		Score:   g.Scoring.Score,
+		Cleared: g.Scoring.LinesCleared,
+		Level:   g.Scoring.Level,
+		// A copy, the writer encodes it while the game moves on
+		Board: append([]Block(nil), g.placedBlocks...),
+	})
+}
+
+func (s *VersusSession) onGameOver() {
+	if s.Result != "" {
+		return
+	}
+
+	s.Result = VersusLose
+	s.send(VersusMessage{Type: MessageGameOver})
+}
+
+// NewVersusGame creates a game seeded from the session so both players get
+// the same pieces.
+func NewVersusGame(session *VersusSession) *Game {
+	g := NewGame()
+	g.HighScores = nil
+	g.playVersus(session)
+	return g
+}
+
+// playVersus puts g in the match of session, starting from the shared seed
+func (g *Game) playVersus(session *VersusSession) {
+	g.Mode = ModeVersus
+	g.Versus = session
+	g.GarbageTarget = session
+
+	g.Seed = session.Seed
+	g.rng = NewRandomizer(session.Seed)
+	g.Player = NewPlayer(g.generatePiece())
+}
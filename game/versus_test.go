@@ -0,0 +1,260 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// connectVersus runs both sides of the handshake over an in-memory connection
+func connectVersus(t *testing.T, seed int64) (host, join *VersusSession) {
+	t.Helper()
+
+	hostConn, joinConn := net.Pipe()
+
+	joined := make(chan error, 1)
+	go func() {
+		var err error
+		join, err = JoinVersusConn(joinConn)
+		joined <- err
+	}()
+
+	host, err := HostVersusConn(hostConn, seed)
+	if err != nil {
+		t.Fatalf("host: %v", err)
+	}
+	if err := <-joined; err != nil {
+		t.Fatalf("join: %v", err)
+	}
+
+	t.Cleanup(func() {
+		host.Close()
+		join.Close()
+	})
+	return host, join
+}
+
+func versusGame(session *VersusSession) *Game {
+	g := NewHeadlessGame(session.Seed)
+	g.playVersus(session)
+	return g
+}
+
+// waitFor applies the opponent's messages until done holds
+func waitFor(t *testing.T, g *Game, what string, done func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+		g.Versus.Update(g)
+	}
+}
+
+// clearRows fills the bottom rows but column 0 and drops a vertical piece
+// into the gap
+func clearRows(g *Game, rows int) {
+	var blocks []Block
+	for y := GameFieldHeight - rows; y < GameFieldHeight; y++ {
+		for x := 1; x < GameFieldWidth; x++ {
+			g.placedBlocks = append(g.placedBlocks, Block{Position: Position{X: x, Y: y}, Color: "red"})
+		}
+		blocks = append(blocks, Block{Position: Position{X: 0, Y: len(blocks)}, Color: "cyan"})
+	}
+
+	g.Player.CurrentPolymino = NewPolyomino(blocks, 0, 0, false)
+	g.HardDrop()
+}
+
+func countColor(blocks []Block, color string) int {
+	count := 0
+	for _, block := range blocks {
+		if block.Color == color {
+			count++
+		}
+	}
+	return count
+}
+
+func TestVersusHandshakeSharesSeed(t *testing.T) {
+	host, join := connectVersus(t, 42)
+
+	if join.Seed != 42 {
+		t.Fatalf("joined with seed %d, want 42", join.Seed)
+	}
+
+	hostGame, joinGame := versusGame(host), versusGame(join)
+	for i := 0; i < 20; i++ {
+		hostPiece, joinPiece := hostGame.generatePiece(), joinGame.generatePiece()
+		if !reflect.DeepEqual(hostPiece.Blocks, joinPiece.Blocks) {
+			t.Fatalf("piece %d differs: %v and %v", i, hostPiece.Blocks, joinPiece.Blocks)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if hostHole, joinHole := host.garbageRng.Intn(GameFieldWidth), join.garbageRng.Intn(GameFieldWidth); hostHole != joinHole {
+			t.Fatalf("garbage hole %d differs: %d and %d", i, hostHole, joinHole)
+		}
+	}
+}
+
+func TestVersusHandshakeRejectsOtherVersions(t *testing.T) {
+	hostConn, joinConn := net.Pipe()
+	defer hostConn.Close()
+
+	go json.NewEncoder(hostConn).Encode(VersusMessage{Version: VersusProtocolVersion + 1, Type: MessageHello, Seed: 1})
+
+	if _, err := JoinVersusConn(joinConn); err == nil {
+		t.Fatal("joined a host with another protocol version")
+	}
+}
+
+func TestVersusHandshakeRejectsOtherMessages(t *testing.T) {
+	hostConn, joinConn := net.Pipe()
+	defer hostConn.Close()
+
+	go json.NewEncoder(hostConn).Encode(VersusMessage{Version: VersusProtocolVersion, Type: MessageState})
+
+	if _, err := JoinVersusConn(joinConn); err == nil {
+		t.Fatal("joined a host that did not say hello")
+	}
+}
+
+func TestVersusClearSendsGarbage(t *testing.T) {
+	tests := []struct {
+		rows    int
+		garbage int
+	}{
+		{rows: 2, garbage: 1},
+		{rows: 3, garbage: 2},
+		{rows: 4, garbage: 4},
+	}
+
+	for _, tt := range tests {
+		host, join := connectVersus(t, 7)
+		hostGame, joinGame := versusGame(host), versusGame(join)
+
+		clearRows(hostGame, tt.rows)
+		if hostGame.Scoring.LinesCleared != tt.rows {
+			t.Fatalf("%d rows: cleared %d lines", tt.rows, hostGame.Scoring.LinesCleared)
+		}
+
+		// The state follows the garbage, so once it is in all garbage is
+		waitFor(t, joinGame, "the host's state", func() bool { return join.Opponent.Updated })
+		if got := joinGame.Garbage.Pending(); got != tt.garbage {
+			t.Errorf("%d rows: %d garbage rows arrived, want %d", tt.rows, got, tt.garbage)
+		}
+	}
+}
+
+func TestVersusClearCancelsGarbage(t *testing.T) {
+	host, join := connectVersus(t, 7)
+	hostGame, joinGame := versusGame(host), versusGame(join)
+
+	// Three rows are waiting, a three-line clear cancels two of them
+	joinGame.Garbage.Push(3, 5)
+	clearRows(joinGame, 3)
+
+	if got := joinGame.Garbage.Pending(); got != 0 {
+		t.Errorf("%d garbage rows still queued, want 0", got)
+	}
+	if got, want := countColor(joinGame.placedBlocks, GarbageColor), GameFieldWidth-1; got != want {
+		t.Errorf("%d garbage blocks rose, want one row of %d", got, want)
+	}
+
+	waitFor(t, hostGame, "the joiner's state", func() bool { return host.Opponent.Updated })
+	if got := hostGame.Garbage.Pending(); got != 0 {
+		t.Errorf("cancelled clear sent %d garbage rows", got)
+	}
+}
+
+func TestVersusGarbageFromPeerIsBounded(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines int
+		hole  int
+		want  int
+	}{
+		{"ordinary attack", 4, 3, 4},
+		{"more than a field", 1000000000, 3, GameFieldHeight},
+		{"negative lines", -5, 3, 0},
+		{"hole left of the field", 2, -1, 0},
+		{"hole right of the field", 2, GameFieldWidth, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewHeadlessGame(1)
+			session := &VersusSession{}
+			session.handle(g, VersusMessage{Type: MessageGarbage, Lines: tt.lines, Hole: tt.hole})
+
+			if got := g.Garbage.Pending(); got != tt.want {
+				t.Errorf("%d garbage rows queued, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersusGameOverReachesPeer(t *testing.T) {
+	host, join := connectVersus(t, 7)
+	hostGame, joinGame := versusGame(host), versusGame(join)
+
+	joinGame.endGame()
+	if join.Result != VersusLose {
+		t.Errorf("loser's result is %q, want %q", join.Result, VersusLose)
+	}
+
+	waitFor(t, hostGame, "the game over", func() bool { return hostGame.IsGameOver })
+	if host.Result != VersusWin {
+		t.Errorf("winner's result is %q, want %q", host.Result, VersusWin)
+	}
+}
+
+func TestVersusCloseReachesPeer(t *testing.T) {
+	host, join := connectVersus(t, 7)
+	joinGame := versusGame(join)
+
+	host.Close()
+
+	waitFor(t, joinGame, "the bye", func() bool { return join.Opponent.Left })
+	if join.Result != VersusWin || !joinGame.IsGameOver {
+		t.Errorf("peer left: result %q, game over %v", join.Result, joinGame.IsGameOver)
+	}
+}
+
+// A peer that stops reading must not hold up the game loop
+func TestVersusSendDoesNotBlock(t *testing.T) {
+	hostConn, joinConn := net.Pipe()
+	defer joinConn.Close()
+
+	go func() {
+		reader := bufio.NewReader(joinConn)
+		reader.ReadBytes('\n')
+		json.NewEncoder(joinConn).Encode(VersusMessage{Version: VersusProtocolVersion, Type: MessageHello, Seed: 7})
+	}()
+	host, err := HostVersusConn(hostConn, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := versusGame(host)
+	done := make(chan bool)
+	go func() {
+		for i := 0; i < 1000; i++ {
+			host.sendState(g)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sending states blocked on a peer that does not read")
+	}
+	host.Close()
+}
@@ -8,5 +8,5 @@ require github.com/mattn/go-runewidth v0.0.9 // indirect
 
 require (
 	github.com/nsf/termbox-go v1.1.1
-	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
+	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a
 )
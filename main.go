@@ -1,9 +1,443 @@
 package main
 
-import "consoleinvaders/game"
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
 
-var Game = game.NewGame()
+	"consoleinvaders/game"
+)
 
 func main() {
-	Game.Start()
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sim":
+			runSimulation(os.Args[2:])
+			return
+		case "tune":
+			runTuning(os.Args[2:])
+			return
+		case "versus":
+			runVersus(os.Args[2:])
+			return
+		case "split":
+			runSplitScreen(os.Args[2:])
+			return
+		case "watch":
+			runWatch(os.Args[2:])
+			return
+		case "puzzle":
+			runPuzzle(os.Args[2:])
+			return
+		case "edit":
+			runEditor(os.Args[2:])
+			return
+		}
+	}
+
+	autoplay := flag.Bool("autoplay", false, "let the built-in bot play")
+	weightsPath := flag.String("weights", "", "bot weights file written by the tune command")
+	broadcast := flag.String("broadcast", "", "stream the game to spectators on this address, e.g. localhost:7778")
+	httpAddr := flag.String("http", "", "serve the JSON control API on this address, e.g. localhost:8080")
+	events := flag.String("events", "", "append game events as JSON lines to this file, or fd:N")
+	logLevel := flag.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logFile := flag.String("log-file", game.LogPath(), "rotating log file, empty to disable")
+	noAnimations := flag.Bool("no-animations", false, "show line clears, hard drops and level ups without animating them")
+	mouse := mouseFlag(flag.CommandLine)
+	script := scriptFlag(flag.CommandLine)
+	seed := flag.Int64("seed", 0, "seed of the first game, 0 for a random one")
+	gravity := gravityFlag(flag.CommandLine)
+	pieces := pieceFlags(flag.CommandLine)
+	theme, glyphs := appearanceFlags(flag.CommandLine)
+	flag.Parse()
+
+	setupLogging(*logLevel, *logFile)
+	applyAppearance(*theme, *glyphs)
+	defer game.GetLoggerInstance().Close()
+
+	g := game.NewGame()
+	if *seed != 0 {
+		g.ResetWithSeed(*seed)
+	}
+	g.UI.Animations.Enabled = !*noAnimations
+	g.Gravity = parseGravity(*gravity)
+	pieces.apply(g)
+	if *mouse {
+		g.EnableMouse()
+	}
+	if *script != "" {
+		useScript(g, *script)
+	}
+	if *autoplay {
+		g.EnableAutoplay(game.NewBot(loadWeights(*weightsPath)))
+	}
+
+	if *broadcast != "" {
+		server, err := game.StartBroadcast(*broadcast)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not start broadcast:", err)
+			os.Exit(1)
+		}
+		defer server.Close()
+		g.Broadcast = server
+	}
+
+	if *httpAddr != "" {
+		api, err := game.StartAPI(*httpAddr)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not start API:", err)
+			os.Exit(1)
+		}
+		defer api.Close()
+		g.API = api
+	}
+
+	if *events != "" {
+		sink, err := game.OpenEventSink(*events)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Could not open event sink:", err)
+			os.Exit(1)
+		}
+		defer sink.Close()
+		g.Events.Subscribe(game.JSONLinesSink(sink))
+	}
+
+	play(g.Start)
+}
+
+func setupLogging(levelName, path string) {
+	logger := game.GetLoggerInstance()
+
+	level, err := game.ParseLogLevel(levelName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	logger.SetLevel(level)
+
+	if path != "" {
+		if err := logger.SetFile(path); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not open log file:", err)
+		}
+	}
+}
+
+// play runs a game loop and exits when there is no input to read
+func play(start func() error) {
+	if err := start(); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not read input:", err)
+		os.Exit(1)
+	}
+}
+
+func scriptFlag(flags *flag.FlagSet) *string {
+	return flags.String("script", "", "play the keys from this script file (- for stdin) instead of the keyboard")
+}
+
+// useScript replaces the keyboard with a script. Scripted runs leave the
+// player's autosave and high scores alone.
+func useScript(g *game.Game, path string) {
+	script, err := game.LoadScript(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not load script:", err)
+		os.Exit(1)
+	}
+	g.Input = script
+	g.HighScores = nil
+}
+
+func mouseFlag(flags *flag.FlagSet) *bool {
+	return flags.Bool("mouse", false, "click a column to move the piece there, scroll to rotate, right-click to drop")
+}
+
+func gravityFlag(flags *flag.FlagSet) *string {
+	return flags.String("gravity", game.GravityNaive, "what the blocks above a cleared row do: naive, sticky or cascade")
+}
+
+func parseGravity(name string) string {
+	gravity, err := game.ParseGravity(name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	return gravity
+}
+
+type pieceOptions struct {
+	set   *string
+	sizes *string
+	grow  *bool
+}
+
+func pieceFlags(flags *flag.FlagSet) pieceOptions {
+	names := strings.Join(game.PieceSetNames(), ", ")
+	return pieceOptions{
+		set:   flags.String("piece-set", "", "draw pieces from "+names+" or a piece set file instead of random polyominoes"),
+		sizes: flags.String("piece-sizes", "2-6", fmt.Sprintf("smallest and biggest random piece in blocks, up to %d", game.MaxPieceSize)),
+		grow:  flags.Bool("grow-pieces", false, "start with small pieces and allow bigger ones as the level rises"),
+	}
+}
+
+func (o pieceOptions) load() (*game.PieceSet, game.PieceSizes) {
+	set, err := game.LoadPieceSet(*o.set)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not load piece set:", err)
+		os.Exit(1)
+	}
+
+	sizes, err := game.ParsePieceSizes(*o.sizes)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	sizes.Grow = *o.grow
+
+	return set, sizes
+}
+
+// apply sets the pieces up and restarts the games, so their first piece
+// follows them too
+func (o pieceOptions) apply(games ...*game.Game) {
+	set, sizes := o.load()
+	for _, g := range games {
+		g.PieceSizes = sizes
+		g.SetPieceSet(set)
+	}
+}
+
+func appearanceFlags(flags *flag.FlagSet) (theme, glyphs *string) {
+	theme = flags.String("theme", "", "classic, vivid, mono or a theme file (default theme.json in the data dir, else classic)")
+	glyphs = flags.String("glyphs", "", "unicode, single or ascii characters (default from the theme, else detected from the locale)")
+	return theme, glyphs
+}
+
+func applyAppearance(themeName, glyphsName string) {
+	theme, err := game.LoadTheme(themeName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not load theme:", err)
+		os.Exit(1)
+	}
+
+	if glyphsName == "" {
+		glyphsName = theme.Glyphs
+	}
+	glyphs, err := game.GlyphSetByName(glyphsName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	renderer := game.GetRendererInstance()
+	renderer.SetTheme(theme)
+	renderer.SetGlyphs(glyphs)
+}
+
+func loadWeights(path string) game.BotWeights {
+	if path == "" {
+		return game.DefaultBotWeights()
+	}
+
+	weights, err := game.LoadBotWeights(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not load bot weights:", err)
+		os.Exit(1)
+	}
+	return weights
+}
+
+func runSimulation(args []string) {
+	flags := flag.NewFlagSet("sim", flag.ExitOnError)
+	games := flags.Int("games", 1000, "number of games to play")
+	workers := flags.Int("workers", runtime.NumCPU(), "games played in parallel")
+	seed := flags.Int64("seed", 1, "seed of the first game, game i uses seed+i")
+	pieces := flags.Int("pieces", 1000, "stop a game after this many pieces, 0 for no limit")
+	weightsPath := flags.String("weights", "", "bot weights file written by the tune command")
+	pieceOpts := pieceFlags(flags)
+	flags.Parse(args)
+
+	game.GetLoggerInstance().SetEnabled(false)
+	pieceSet, pieceSizes := pieceOpts.load()
+
+	results := game.Simulate(game.SimulationConfig{
+		Games:      *games,
+		Workers:    *workers,
+		Seed:       *seed,
+		MaxPieces:  *pieces,
+		Weights:    loadWeights(*weightsPath),
+		PieceSet:   pieceSet,
+		PieceSizes: pieceSizes,
+	})
+
+	fmt.Print(game.FormatSimulationReport(results))
+}
+
+func runTuning(args []string) {
+	flags := flag.NewFlagSet("tune", flag.ExitOnError)
+	generations := flags.Int("generations", 10, "optimizer generations")
+	population := flags.Int("population", 30, "candidate weight sets per generation")
+	elite := flags.Int("elite", 6, "best candidates the next generation is sampled around")
+	games := flags.Int("games", 10, "games played by every candidate")
+	pieces := flags.Int("pieces", 500, "stop a game after this many pieces")
+	workers := flags.Int("workers", runtime.NumCPU(), "games played in parallel")
+	seed := flags.Int64("seed", 1, "seed for the optimizer and the games")
+	out := flags.String("out", "weights.json", "file the best weights are written to")
+	pieceOpts := pieceFlags(flags)
+	flags.Parse(args)
+
+	game.GetLoggerInstance().SetEnabled(false)
+	pieceSet, pieceSizes := pieceOpts.load()
+
+	best := game.TuneWeights(game.TuneConfig{
+		Generations: *generations,
+		Population:  *population,
+		Elite:       *elite,
+		Games:       *games,
+		MaxPieces:   *pieces,
+		Workers:     *workers,
+		Seed:        *seed,
+		PieceSet:    pieceSet,
+		PieceSizes:  pieceSizes,
+	}, func(generation int, best game.BotWeights, fitness float64) {
+		fmt.Printf("generation %d: mean lines %.1f with %+v\n", generation, fitness, best)
+		// Keep the best set so far on disk in case the run is interrupted
+		if err := game.SaveBotWeights(*out, best); err != nil {
+			fmt.Fprintln(os.Stderr, "Could not write weights:", err)
+		}
+	})
+
+	if err := game.SaveBotWeights(*out, best); err != nil {
+		fmt.Fprintln(os.Stderr, "Could not write weights:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Wrote", *out)
+}
+
+func runVersus(args []string) {
+	flags := flag.NewFlagSet("versus", flag.ExitOnError)
+	host := flags.String("host", "", "listen on this address, e.g. :7777")
+	join := flags.String("join", "", "connect to a hosting player, e.g. 192.168.1.20:7777")
+	theme, glyphs := appearanceFlags(flags)
+	flags.Parse(args)
+
+	applyAppearance(*theme, *glyphs)
+
+	var session *game.VersusSession
+	var err error
+	switch {
+	case *host != "":
+		fmt.Println("Waiting for an opponent on", *host)
+		session, err = game.HostVersus(*host, game.NewSeed())
+	case *join != "":
+		session, err = game.JoinVersus(*join)
+	default:
+		fmt.Fprintln(os.Stderr, "versus needs -host or -join")
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not start versus:", err)
+		os.Exit(1)
+	}
+	defer session.Close()
+
+	play(game.NewVersusGame(session).Start)
+}
+
+func runSplitScreen(args []string) {
+	flags := flag.NewFlagSet("split", flag.ExitOnError)
+	garbage := flags.Bool("garbage", false, "send garbage rows to the other board on multi-line clears")
+	noAnimations := flags.Bool("no-animations", false, "show line clears, hard drops and level ups without animating them")
+	gravity := gravityFlag(flags)
+	pieces := pieceFlags(flags)
+	theme, glyphs := appearanceFlags(flags)
+	flags.Parse(args)
+
+	applyAppearance(*theme, *glyphs)
+
+	split := game.NewSplitScreen(*garbage)
+	for _, g := range split.Games {
+		g.UI.Animations.Enabled = !*noAnimations
+		g.Gravity = parseGravity(*gravity)
+	}
+	pieces.apply(split.Games...)
+	play(split.Start)
+}
+
+func runWatch(args []string) {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	addr := flags.String("addr", "localhost:7778", "address of the broadcasting game")
+	theme, glyphs := appearanceFlags(flags)
+	flags.Parse(args)
+
+	applyAppearance(*theme, *glyphs)
+
+	if err := game.Watch(*addr); err != nil {
+		fmt.Fprintln(os.Stderr, "Watch ended:", err)
+		os.Exit(1)
+	}
+}
+
+func runPuzzle(args []string) {
+	flags := flag.NewFlagSet("puzzle", flag.ExitOnError)
+	noAnimations := flags.Bool("no-animations", false, "show line clears, hard drops and level ups without animating them")
+	mouse := mouseFlag(flags)
+	script := scriptFlag(flags)
+	theme, glyphs := appearanceFlags(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: puzzle [flags] <name or puzzle file>")
+		fmt.Fprintln(os.Stderr, "built-in puzzles:")
+		for _, name := range game.PuzzleNames() {
+			fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, game.BuiltinPuzzles[name].Description)
+		}
+		os.Exit(2)
+	}
+
+	puzzle, err := game.LoadPuzzle(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Could not load puzzle:", err)
+		os.Exit(1)
+	}
+
+	applyAppearance(*theme, *glyphs)
+
+	g := game.NewPuzzleGame(puzzle)
+	g.UI.Animations.Enabled = !*noAnimations
+	if *mouse {
+		g.EnableMouse()
+	}
+	if *script != "" {
+		useScript(g, *script)
+	}
+	play(g.Start)
+}
+
+func runEditor(args []string) {
+	flags := flag.NewFlagSet("edit", flag.ExitOnError)
+	theme, glyphs := appearanceFlags(flags)
+	flags.Parse(args)
+
+	// Start from the file if it exists, a built-in puzzle is copied to <name>.json
+	path := "puzzle.json"
+	if flags.NArg() > 0 {
+		path = flags.Arg(0)
+	}
+
+	var puzzle game.Puzzle
+	if builtin, ok := game.BuiltinPuzzles[path]; ok {
+		puzzle = builtin
+		path += ".json"
+	} else if loaded, err := game.LoadPuzzle(path); err == nil {
+		puzzle = loaded
+	} else if !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "Could not load puzzle:", err)
+		os.Exit(1)
+	}
+
+	applyAppearance(*theme, *glyphs)
+
+	play(game.NewEditor(puzzle, path).Start)
 }